@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Binance returns at most this many klines per request
+const maxKlinesPerRequest = 1000
+
+// Kline is one OHLC candle from /api/v3/klines
+type Kline struct {
+	OpenTime time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+}
+
+// backtestPosition is an open simulated position during a backtest
+type backtestPosition struct {
+	BuyTime      time.Time
+	BuyPrice     float64
+	Quantity     float64
+	Invested     float64
+	BuyFee       float64
+	Target       float64
+	HighestPrice float64
+	Drop         float64
+}
+
+// RunBacktest replays the drop strategy over historical daily candles for one symbol and prints the results
+func RunBacktest(args []string) {
+	if len(args) != 3 {
+		fmt.Println("Usage: ./trading-bot backtest <symbol> <start YYYY-MM-DD> <end YYYY-MM-DD>")
+		fmt.Println("Example: ./trading-bot backtest ADA 2024-01-01 2024-12-31")
+		os.Exit(1)
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(args[0]))
+	if !strings.HasSuffix(symbol, "USDT") {
+		symbol += "USDT"
+	}
+
+	start, err := time.Parse("2006-01-02", args[1])
+	if err != nil {
+		log.Fatalf("ERROR: Invalid start date %q: %v", args[1], err)
+	}
+	end, err := time.Parse("2006-01-02", args[2])
+	if err != nil {
+		log.Fatalf("ERROR: Invalid end date %q: %v", args[2], err)
+	}
+	if !end.After(start) {
+		log.Fatalf("ERROR: End date must be after start date")
+	}
+
+	// Backtests never place orders, so reuse the dry run path to build the bot without API keys
+	os.Setenv("DRY_RUN", "true")
+	bot, err := NewTradingBot(getEnvFloat("DRY_RUN_BALANCE", 100.0))
+	if err != nil {
+		log.Fatalf("ERROR: Failed to initialize bot config: %v", err)
+	}
+
+	// Include the day before the start so the first day has a 24h change
+	klines, err := bot.fetchDailyKlines(symbol, start.AddDate(0, 0, -1), end)
+	if err != nil {
+		log.Fatalf("ERROR: Failed to fetch klines: %v", err)
+	}
+	if len(klines) < 2 {
+		log.Fatalf("ERROR: Not enough daily candles for %s between %s and %s", symbol, args[1], args[2])
+	}
+
+	bot.runBacktest(symbol, klines)
+}
+
+// fetchDailyKlines fetches daily candles for a symbol between start and end, paging through the 1000-candle limit
+func (bot *TradingBot) fetchDailyKlines(symbol string, start, end time.Time) ([]Kline, error) {
+	klines := make([]Kline, 0)
+
+	for from := start; from.Before(end); {
+		params := url.Values{}
+		params.Set("symbol", symbol)
+		params.Set("interval", "1d")
+		params.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+		params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+		params.Set("limit", strconv.Itoa(maxKlinesPerRequest))
+
+		resp, err := bot.httpClient.Get(bot.Binance.Config.BaseURL + "/api/v3/klines?" + params.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("error getting klines: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading klines response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("klines request failed: %w", parseBinanceError(resp.StatusCode, body))
+		}
+
+		// Each kline is a mixed array: [openTime, "open", "high", "low", "close", ...]
+		var raw [][]interface{}
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, fmt.Errorf("error parsing klines: %v", err)
+		}
+
+		for _, k := range raw {
+			if len(k) < 5 {
+				continue
+			}
+			openTime, _ := k[0].(float64)
+			kline := Kline{OpenTime: time.UnixMilli(int64(openTime)).UTC()}
+			kline.Open, _ = strconv.ParseFloat(fmt.Sprint(k[1]), 64)
+			kline.High, _ = strconv.ParseFloat(fmt.Sprint(k[2]), 64)
+			kline.Low, _ = strconv.ParseFloat(fmt.Sprint(k[3]), 64)
+			kline.Close, _ = strconv.ParseFloat(fmt.Sprint(k[4]), 64)
+			klines = append(klines, kline)
+		}
+
+		if len(raw) < maxKlinesPerRequest {
+			break
+		}
+		from = klines[len(klines)-1].OpenTime.Add(24 * time.Hour)
+	}
+
+	return klines, nil
+}
+
+// runBacktest simulates the strategy candle by candle: buy at the close of a day whose change is in the
+// buy band, then sell at the target (limit fill) or the configured stop. Stops are checked before the
+// target within a day, so results err on the pessimistic side.
+func (bot *TradingBot) runBacktest(symbol string, klines []Kline) {
+	coinName := strings.TrimSuffix(symbol, "USDT")
+	startBudget := bot.AvailableBudget
+	positions := make([]backtestPosition, 0)
+
+	fmt.Printf("\n=== BACKTEST %s: %s to %s (%d daily candles) ===\n", coinName,
+		klines[1].OpenTime.Format("2006-01-02"), klines[len(klines)-1].OpenTime.Format("2006-01-02"), len(klines)-1)
+	fmt.Printf("Buy band: %s | Target: +%.1f%% net | Investment: %s | Budget: %.2f USDT\n",
+		bot.dropBandLabel(), bot.ProfitTargetPercent, bot.investmentLabel(), startBudget)
+	if bot.SimulatedSlippage > 0 {
+		fmt.Printf("Simulated slippage: %.2f%% against market buys and stop exits\n", bot.SimulatedSlippage)
+	}
+
+	for i := 1; i < len(klines); i++ {
+		day := klines[i]
+
+		// Exits first: positions bought on earlier days can hit their stop or target today
+		remaining := positions[:0]
+		for _, pos := range positions {
+			stopPrice := 0.0
+			if bot.TrailingStopPercent > 0 {
+				stopPrice = pos.HighestPrice * (1 - bot.TrailingStopPercent/100)
+			} else if bot.StopLossPercent > 0 {
+				stopPrice = pos.BuyPrice * (1 - bot.StopLossPercent/100)
+			}
+
+			switch {
+			case stopPrice > 0 && day.Low <= stopPrice:
+				// Gap downs fill at the open, not the stop, and the market sell slips below that
+				sellPrice := bot.slippedPrice(math.Min(stopPrice, day.Open), SideSell)
+				bot.closeBacktestPosition(symbol, pos, sellPrice, bot.TakerFeePercent, day.OpenTime, "STOP")
+			case day.High >= pos.Target:
+				bot.closeBacktestPosition(symbol, pos, pos.Target, bot.sellFeePercent(), day.OpenTime, "TARGET")
+			default:
+				pos.HighestPrice = math.Max(pos.HighestPrice, day.High)
+				remaining = append(remaining, pos)
+			}
+		}
+		positions = remaining
+
+		// Entries: the same band and safety limit as analyzeTradingOpportunities, using the daily close
+		prevClose := klines[i-1].Close
+		if prevClose <= 0 {
+			continue
+		}
+		change := (day.Close - prevClose) / prevClose * 100
+		if bot.classifyDropSignal(change) != SignalBuy {
+			continue
+		}
+		amount := bot.sizeForDrop(bot.tradeAmount(0), change, 0)
+		if amount <= 0 || bot.AvailableBudget < amount || len(positions) >= bot.MaxOpenPositions {
+			fmt.Printf("%s  SKIP   %.2f%% drop (budget or position limit)\n", day.OpenTime.Format("2006-01-02"), change)
+			continue
+		}
+
+		buyFee := amount * bot.buyFeePercent() / 100
+		buyPrice := bot.slippedPrice(day.Close, SideBuy)
+		pos := backtestPosition{
+			BuyTime:      day.OpenTime,
+			BuyPrice:     buyPrice,
+			Quantity:     (amount - buyFee) / buyPrice,
+			Invested:     amount,
+			BuyFee:       buyFee,
+			Target:       bot.targetSellPrice(buyPrice),
+			HighestPrice: day.Close,
+			Drop:         change,
+		}
+		positions = append(positions, pos)
+		bot.AvailableBudget -= amount
+		fmt.Printf("%s  BUY    $%.6f after %.2f%% drop (target $%.6f)\n",
+			day.OpenTime.Format("2006-01-02"), pos.BuyPrice, change, pos.Target)
+	}
+
+	bot.Positions = make([]TradingPosition, 0, len(positions))
+	lastClose := klines[len(klines)-1].Close
+	openValue := 0.0
+	for _, pos := range positions {
+		openValue += pos.Quantity * lastClose
+		bot.Positions = append(bot.Positions, TradingPosition{Symbol: symbol, BuyPrice: pos.BuyPrice, Quantity: pos.Quantity})
+	}
+
+	bot.printStats()
+	fmt.Printf("Still open at the end: %d (worth %.2f USDT at the last close)\n", len(positions), openValue)
+	fmt.Printf("Ending equity: %.2f USDT (started with %.2f USDT, %+.2f%%)\n",
+		bot.AvailableBudget+openValue, startBudget, (bot.AvailableBudget+openValue-startBudget)/startBudget*100)
+}
+
+// closeBacktestPosition books a simulated sell into the budget and performance stats
+func (bot *TradingBot) closeBacktestPosition(symbol string, pos backtestPosition, sellPrice, feePercent float64, sellTime time.Time, reason string) {
+	sellFee := sellPrice * pos.Quantity * feePercent / 100
+	proceeds := sellPrice*pos.Quantity - sellFee
+	profit := proceeds - pos.Invested
+
+	trade := CompletedTrade{
+		ID:             bot.Stats.TotalTrades + 1,
+		Symbol:         symbol,
+		BuyPrice:       pos.BuyPrice,
+		SellPrice:      sellPrice,
+		Quantity:       pos.Quantity,
+		InvestedAmount: pos.Invested,
+		Fees:           pos.BuyFee + sellFee,
+		Profit:         profit,
+		ProfitPercent:  profit / pos.Invested * 100,
+		BuyTime:        pos.BuyTime,
+		SellTime:       sellTime,
+		HoldDuration:   sellTime.Sub(pos.BuyTime),
+	}
+
+	bot.CompletedTrades = append(bot.CompletedTrades, trade)
+	bot.AvailableBudget += proceeds
+	bot.updateStats(trade)
+
+	fmt.Printf("%s  %-6s $%.6f (bought $%.6f) P/L %+.2f USDT (%+.2f%%)\n",
+		sellTime.Format("2006-01-02"), reason, sellPrice, pos.BuyPrice, trade.Profit, trade.ProfitPercent)
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// BinanceClient sends requests to the Binance spot API. It only handles transport and signing;
+// dry run, rounding and retries stay with the TradingBot.
+type BinanceClient struct {
+	Config     BinanceConfig
+	httpClient *http.Client
+}
+
+// NewBinanceClient creates a Binance client that sends its requests through httpClient
+func NewBinanceClient(config BinanceConfig, httpClient *http.Client) *BinanceClient {
+	return &BinanceClient{Config: config, httpClient: httpClient}
+}
+
+// OrderPrecision is the number of decimal places a symbol accepts for order quantities and prices
+type OrderPrecision struct {
+	Quantity int
+	Price    int
+}
+
+// defaultOrderPrecision is used when a symbol's filters are unavailable
+var defaultOrderPrecision = OrderPrecision{Quantity: 8, Price: 8}
+
+// formatDecimal formats value with at most decimals decimal places, trimming trailing zeros ("0.12300000" -> "0.123")
+func formatDecimal(value float64, decimals int) string {
+	formatted := strconv.FormatFloat(value, 'f', decimals, 64)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
+
+// sign creates the HMAC SHA256 signature Binance expects for a query string
+func (c *BinanceClient) sign(queryString string) string {
+	mac := hmac.New(sha256.New, []byte(c.Config.SecretKey))
+	mac.Write([]byte(queryString))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedRequest adds the timestamp, recvWindow and signature to params and sends them to path.
+// POST requests carry the parameters in the form body, everything else in the query string.
+// action names the request in error messages, e.g. "buy order".
+func (c *BinanceClient) signedRequest(method, path string, params url.Values, action string) ([]byte, error) {
+	if c.Config.APIKey == "" || c.Config.SecretKey == "" {
+		return nil, fmt.Errorf("Binance API credentials not configured")
+	}
+
+	params.Set("timestamp", fmt.Sprintf("%d", binanceTimestamp()))
+	params.Set("recvWindow", fmt.Sprintf("%d", recvWindowMs()))
+
+	queryString := params.Encode()
+	signed := queryString + "&signature=" + c.sign(queryString)
+
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequest(method, c.Config.BaseURL+path, strings.NewReader(signed))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequest(method, c.Config.BaseURL+path+"?"+signed, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s request: %v", action, err)
+	}
+
+	req.Header.Set("X-MBX-APIKEY", c.Config.APIKey)
+
+	return c.do(req, action)
+}
+
+// publicGet sends an unsigned GET request to path
+func (c *BinanceClient) publicGet(path string, action string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, c.Config.BaseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s request: %v", action, err)
+	}
+
+	return c.do(req, action)
+}
+
+// do sends a request and returns the body of a successful response, or the Binance error otherwise
+func (c *BinanceClient) do(req *http.Request, action string) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error executing %s: %v", action, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s response: %v", action, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s failed: %w", action, parseBinanceError(resp.StatusCode, body))
+	}
+
+	return body, nil
+}
+
+// orderRequest sends a signed order request and parses the order in the response
+func (c *BinanceClient) orderRequest(method, path string, params url.Values, action string) (*OrderResponse, error) {
+	body, err := c.signedRequest(method, path, params, action)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderResp OrderResponse
+	if err := json.Unmarshal(body, &orderResp); err != nil {
+		return nil, fmt.Errorf("error parsing %s response: %v", action, err)
+	}
+
+	return &orderResp, nil
+}
+
+// Buy places a market buy spending quoteOrderQty USDT, formatted with quotePrecision decimals
+func (c *BinanceClient) Buy(symbol string, quoteOrderQty float64, quotePrecision int) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(SideBuy))
+	params.Set("type", string(OrderTypeMarket))
+	params.Set("quoteOrderQty", formatDecimal(quoteOrderQty, quotePrecision))
+
+	return c.orderRequest(http.MethodPost, "/api/v3/order", params, "buy order")
+}
+
+// BuyLimit places a GTC limit buy, asking for the full response so immediate fills are reported
+func (c *BinanceClient) BuyLimit(symbol string, quantity, price float64, prec OrderPrecision) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(SideBuy))
+	params.Set("type", string(OrderTypeLimit))
+	params.Set("timeInForce", "GTC")
+	params.Set("quantity", formatDecimal(quantity, prec.Quantity))
+	params.Set("price", formatDecimal(price, prec.Price))
+	params.Set("newOrderRespType", "FULL")
+
+	return c.orderRequest(http.MethodPost, "/api/v3/order", params, "limit buy order")
+}
+
+// SellLimit places a GTC limit sell
+func (c *BinanceClient) SellLimit(symbol string, quantity, price float64, prec OrderPrecision) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(SideSell))
+	params.Set("type", string(OrderTypeLimit))
+	params.Set("timeInForce", "GTC") // Good Till Cancelled
+	params.Set("quantity", formatDecimal(quantity, prec.Quantity))
+	params.Set("price", formatDecimal(price, prec.Price))
+
+	return c.orderRequest(http.MethodPost, "/api/v3/order", params, "limit sell order")
+}
+
+// SellOCO places an OCO sell: a LIMIT_MAKER at price and a STOP_LOSS_LIMIT triggered at stopPrice
+func (c *BinanceClient) SellOCO(symbol string, quantity, price, stopPrice, stopLimitPrice float64, prec OrderPrecision) (*OCOResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(SideSell))
+	params.Set("quantity", formatDecimal(quantity, prec.Quantity))
+	params.Set("price", formatDecimal(price, prec.Price))
+	params.Set("stopPrice", formatDecimal(stopPrice, prec.Price))
+	params.Set("stopLimitPrice", formatDecimal(stopLimitPrice, prec.Price))
+	params.Set("stopLimitTimeInForce", "GTC")
+
+	body, err := c.signedRequest(http.MethodPost, "/api/v3/order/oco", params, "OCO sell order")
+	if err != nil {
+		return nil, err
+	}
+
+	var ocoResp OCOResponse
+	if err := json.Unmarshal(body, &ocoResp); err != nil {
+		return nil, fmt.Errorf("error parsing OCO sell order response: %v", err)
+	}
+
+	return &ocoResp, nil
+}
+
+// SellMarket places a market sell of quantity; only prec.Quantity is used
+func (c *BinanceClient) SellMarket(symbol string, quantity float64, prec OrderPrecision) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(SideSell))
+	params.Set("type", string(OrderTypeMarket))
+	params.Set("quantity", formatDecimal(quantity, prec.Quantity))
+
+	return c.orderRequest(http.MethodPost, "/api/v3/order", params, "sell order")
+}
+
+// QueryOrder fetches the current state of an order
+func (c *BinanceClient) QueryOrder(symbol string, orderID int64) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", fmt.Sprintf("%d", orderID))
+
+	return c.orderRequest(http.MethodGet, "/api/v3/order", params, "query order")
+}
+
+// CancelOrder cancels an open order and returns the cancelled order
+func (c *BinanceClient) CancelOrder(symbol string, orderID int64) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", fmt.Sprintf("%d", orderID))
+
+	return c.orderRequest(http.MethodDelete, "/api/v3/order", params, "cancel order")
+}
+
+// OpenOrders lists every open order on the account across all symbols
+func (c *BinanceClient) OpenOrders() ([]OrderResponse, error) {
+	body, err := c.signedRequest(http.MethodGet, "/api/v3/openOrders", url.Values{}, "open orders request")
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []OrderResponse
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("error parsing open orders: %v", err)
+	}
+
+	return orders, nil
+}
+
+// AccountInfo fetches the signed account information, including every balance
+func (c *BinanceClient) AccountInfo() (*AccountInfo, error) {
+	body, err := c.signedRequest(http.MethodGet, "/api/v3/account", url.Values{}, "account info request")
+	if err != nil {
+		return nil, err
+	}
+
+	var accountInfo AccountInfo
+	if err := json.Unmarshal(body, &accountInfo); err != nil {
+		return nil, fmt.Errorf("error parsing account response: %v", err)
+	}
+
+	return &accountInfo, nil
+}
+
+// AccountBalance fetches the free (unlocked) balance of an asset, returning 0 if the account holds none
+func (c *BinanceClient) AccountBalance(asset string) (float64, error) {
+	accountInfo, err := c.AccountInfo()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, balance := range accountInfo.Balances {
+		if balance.Asset == asset {
+			free, err := strconv.ParseFloat(balance.Free, 64)
+			if err != nil {
+				return 0, fmt.Errorf("error parsing %s balance: %v", asset, err)
+			}
+			return free, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// ExchangeInfo fetches exchange info for one symbol, or for every symbol when symbol is empty
+func (c *BinanceClient) ExchangeInfo(symbol string) (*ExchangeInfo, error) {
+	path := "/api/v3/exchangeInfo"
+	if symbol != "" {
+		path += "?symbol=" + symbol
+	}
+
+	body, err := c.publicGet(path, "exchange info request")
+	if err != nil {
+		return nil, err
+	}
+
+	var exchangeInfo ExchangeInfo
+	if err := json.Unmarshal(body, &exchangeInfo); err != nil {
+		return nil, fmt.Errorf("error parsing exchange info: %v", err)
+	}
+
+	return &exchangeInfo, nil
+}
+
+// TickerPrice fetches the latest price for a symbol
+func (c *BinanceClient) TickerPrice(symbol string) (float64, error) {
+	body, err := c.publicGet("/api/v3/ticker/price?symbol="+symbol, "ticker price request")
+	if err != nil {
+		return 0, err
+	}
+
+	var ticker struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return 0, fmt.Errorf("error parsing ticker price: %v", err)
+	}
+
+	return strconv.ParseFloat(ticker.Price, 64)
+}
+
+// DustTransfer converts small balances of the given assets to BNB
+func (c *BinanceClient) DustTransfer(assets []string) (*DustTransferResponse, error) {
+	params := url.Values{}
+	for _, asset := range assets {
+		params.Add("asset", asset)
+	}
+
+	body, err := c.signedRequest(http.MethodPost, "/sapi/v1/asset/dust", params, "dust transfer")
+	if err != nil {
+		return nil, err
+	}
+
+	var dustResp DustTransferResponse
+	if err := json.Unmarshal(body, &dustResp); err != nil {
+		return nil, fmt.Errorf("error parsing dust transfer response: %v", err)
+	}
+
+	return &dustResp, nil
+}
+
+// TransferToSubAccount moves an asset from the master spot wallet to a sub-account spot wallet
+func (c *BinanceClient) TransferToSubAccount(email, asset string, amount float64) error {
+	params := url.Values{}
+	params.Set("toEmail", email)
+	params.Set("fromAccountType", "SPOT")
+	params.Set("toAccountType", "SPOT")
+	params.Set("asset", asset)
+	params.Set("amount", strconv.FormatFloat(truncateToPrecision(amount, 8), 'f', -1, 64))
+
+	_, err := c.signedRequest(http.MethodPost, "/sapi/v1/sub-account/universalTransfer", params, "transfer")
+	return err
+}
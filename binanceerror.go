@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Binance error codes the bot reacts to
+const (
+	binanceCodeTooManyRequests  = -1003 // Request weight limit exceeded
+	binanceCodeFilterFailure    = -1013 // Order violates a symbol filter (LOT_SIZE, PRICE_FILTER, NOTIONAL, ...)
+	binanceCodeTimestamp        = -1021 // Timestamp outside recvWindow
+	binanceCodeInvalidSignature = -1022 // Signature doesn't match the secret key
+	binanceCodeNewOrderRejected = -2010 // Order rejected, e.g. the account has insufficient balance
+	binanceCodeBadAPIKeyFormat  = -2014 // API key format invalid
+	binanceCodeRejectedMbxKey   = -2015 // Invalid key, IP or permissions
+)
+
+// ErrBinanceUnavailable is returned when Binance answers 503 without an error body, as during system maintenance
+var ErrBinanceUnavailable = errors.New("Binance service unavailable")
+
+// BinanceError is the {"code": ..., "msg": ...} body Binance returns with a failed request
+type BinanceError struct {
+	HTTPStatus int    `json:"-"`
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+}
+
+func (e *BinanceError) Error() string {
+	return fmt.Sprintf("Binance error %d (HTTP %d): %s", e.Code, e.HTTPStatus, e.Msg)
+}
+
+// Retryable reports whether the same request could succeed if sent again
+func (e *BinanceError) Retryable() bool {
+	switch e.Code {
+	case binanceCodeFilterFailure, binanceCodeNewOrderRejected:
+		return false
+	}
+	return true
+}
+
+// parseBinanceError turns a failed response into a *BinanceError, falling back to the raw body
+// when it isn't Binance's error JSON
+func parseBinanceError(status int, body []byte) error {
+	apiErr := &BinanceError{HTTPStatus: status}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Code == 0 {
+		if status == http.StatusServiceUnavailable {
+			return fmt.Errorf("%w (status %d): %s", ErrBinanceUnavailable, status, string(body))
+		}
+		return fmt.Errorf("status %d: %s", status, string(body))
+	}
+	return apiErr
+}
+
+// isBinanceUnavailable reports whether err is a 503 from Binance, with or without an error body
+func isBinanceUnavailable(err error) bool {
+	var apiErr *BinanceError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus == http.StatusServiceUnavailable
+	}
+	return errors.Is(err, ErrBinanceUnavailable)
+}
+
+// binanceErrorCode returns the Binance error code wrapped in err, or 0 if err isn't a Binance API error
+func binanceErrorCode(err error) int {
+	var apiErr *BinanceError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
+
+// isRetryableOrderError reports whether an order failure is worth retrying; unknown errors are
+// assumed transient (network failures, timeouts)
+func isRetryableOrderError(err error) bool {
+	var apiErr *BinanceError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
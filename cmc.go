@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CoinMarketCap listings endpoint; the limit is filled in per request
+const cmcListingsURL = "https://pro-api.coinmarketcap.com/v1/cryptocurrency/listings/latest?start=1&limit=%d&convert=USD"
+
+// Listings fetched per request (CMC_FETCH_LIMIT): the top 50 normally leave 20 tradeable coins after
+// filtering, and twice as many are fetched when they don't. CMC returns at most 5000 per request.
+const (
+	defaultCMCFetchLimit = 50
+	maxCMCFetchLimit     = 5000
+)
+
+// Retry policy for CoinMarketCap requests: 3 attempts, backing off 1s then 2s
+const (
+	cmcMaxAttempts   = 3
+	cmcRetryBase     = 1 * time.Second
+	cmcMaxRetryAfter = 60 * time.Second // Never block a cycle longer than this on a Retry-After header
+)
+
+// defaultStablecoins are excluded from trading unless STABLECOIN_SYMBOLS overrides the list
+var defaultStablecoins = []string{
+	"USDT", "USDC", "DAI", "TUSD", "FDUSD", "BUSD", "USDP", "USDD", "USDE", "USDS", "PYUSD", "FRAX", "GUSD", "LUSD", "EURC",
+}
+
+// Peg heuristic for stablecoins missing from the list: price within 0.5% of $1 and a 24h move within ±0.5%
+const (
+	stablecoinPegTolerance     = 0.005
+	stablecoinMaxChangePercent = 0.5
+)
+
+// ErrRateLimited is returned when CoinMarketCap rejects requests with HTTP 429
+var ErrRateLimited = errors.New("CoinMarketCap rate limit exceeded")
+
+// fetchCMCListings fetches the CoinMarketCap listings, retrying transient failures with exponential backoff
+func fetchCMCListings(client *http.Client, apiKey string, limit int) (*CoinMarketCapResponse, error) {
+	var lastErr error
+
+	for attempt := 1; attempt <= cmcMaxAttempts; attempt++ {
+		cmcResponse, retryAfter, retryable, err := requestCMCListings(client, apiKey, limit)
+		if err == nil {
+			return cmcResponse, nil
+		}
+		lastErr = err
+
+		if !retryable || attempt == cmcMaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(cmcRetryBase, attempt)
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		log.Printf("WARNING: CMC request failed (attempt %d/%d): %v - retrying in %s", attempt, cmcMaxAttempts, err, delay)
+		time.Sleep(delay)
+	}
+
+	return nil, lastErr
+}
+
+// requestCMCListings makes a single listings request, reporting whether a failure is worth retrying
+// and how long the server asked us to wait
+func requestCMCListings(client *http.Client, apiKey string, limit int) (*CoinMarketCapResponse, time.Duration, bool, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf(cmcListingsURL, limit), nil)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("error creating CMC request: %v", err)
+	}
+
+	req.Header.Set("X-CMC_PRO_API_KEY", apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Network errors and timeouts are transient
+		return nil, 0, true, fmt.Errorf("error making CMC request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, true, fmt.Errorf("error reading CMC response: %v", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), true, rateLimitError(body)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), resp.StatusCode >= 500,
+			fmt.Errorf("CMC API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var cmcResponse CoinMarketCapResponse
+	if err := json.Unmarshal(body, &cmcResponse); err != nil {
+		return nil, 0, false, fmt.Errorf("error parsing CMC JSON: %v", err)
+	}
+
+	if cmcResponse.Status.ErrorCode != 0 {
+		return nil, 0, false, fmt.Errorf("CMC API error: %s", cmcResponse.Status.ErrorMessage)
+	}
+
+	// credit_count is the number of plan credits this call consumed
+	fmt.Printf("CMC credits used by this request: %d\n", cmcResponse.Status.CreditCount)
+
+	return &cmcResponse, 0, false, nil
+}
+
+// rateLimitError builds an ErrRateLimited error with CMC's explanation and credit usage when the body has them
+func rateLimitError(body []byte) error {
+	var cmcResponse CoinMarketCapResponse
+	if err := json.Unmarshal(body, &cmcResponse); err != nil || cmcResponse.Status.ErrorCode == 0 {
+		return fmt.Errorf("%w: %s", ErrRateLimited, string(body))
+	}
+
+	log.Printf("WARNING: CMC rate limited (error %d, credit_count %d): %s",
+		cmcResponse.Status.ErrorCode, cmcResponse.Status.CreditCount, cmcResponse.Status.ErrorMessage)
+	return fmt.Errorf("%w: %s", ErrRateLimited, cmcResponse.Status.ErrorMessage)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date, capped at cmcMaxRetryAfter
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if when, err := http.ParseTime(value); err == nil {
+		delay = time.Until(when)
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+	if delay > cmcMaxRetryAfter {
+		return cmcMaxRetryAfter
+	}
+	return delay
+}
+
+// isStablecoin reports whether a CMC coin is a stablecoin, either by symbol or because it trades like a $1 peg
+func (bot *TradingBot) isStablecoin(symbol string, price, change24h float64) (bool, string) {
+	if bot.StablecoinSymbols[strings.ToUpper(symbol)] {
+		return true, "listed in STABLECOIN_SYMBOLS"
+	}
+
+	if math.Abs(price-1) <= stablecoinPegTolerance && math.Abs(change24h) <= stablecoinMaxChangePercent {
+		return true, fmt.Sprintf("pegged near $1 ($%.4f, %.2f%% 24h)", price, change24h)
+	}
+
+	return false, ""
+}
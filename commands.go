@@ -0,0 +1,654 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ShowStatus prints open positions and budget from the persisted bot state
+func ShowStatus() {
+	path := stateFilePath()
+
+	client := newHTTPClient()
+	bot := &TradingBot{Binance: NewBinanceClient(BinanceConfig{BaseURL: binanceBaseURL()}, client), httpClient: client}
+	if err := bot.LoadState(path); err != nil {
+		log.Fatalf("ERROR: Could not load state from %s: %v", path, err)
+	}
+
+	fmt.Printf("=== Bot Status (state file: %s) ===\n\n", path)
+
+	if len(bot.Positions) == 0 {
+		fmt.Println("No open positions")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "ID\tSYMBOL\tBUY PRICE\tQUANTITY\tINVESTED\tTARGET\tPRICE\tP/L USDT\tP/L %\tAGE\t")
+
+		for i := range bot.Positions {
+			pos := &bot.Positions[i]
+
+			// Refresh with a live price where possible, otherwise fall back to the last saved value
+			currentPrice := 0.0
+			if pos.Quantity > 0 {
+				currentPrice = pos.CurrentValue / pos.Quantity
+			}
+			if livePrice, err := bot.getTickerPrice(pos.Symbol); err == nil {
+				currentPrice = livePrice
+				pos.CurrentValue = livePrice * pos.Quantity
+			}
+
+			pnl := pos.CurrentValue - pos.InvestedAmount
+			pnlPercent := 0.0
+			if pos.InvestedAmount > 0 {
+				pnlPercent = pnl / pos.InvestedAmount * 100
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%.6f\t%.6f\t%.2f\t%.6f\t%.6f\t%+.2f\t%+.2f%%\t%s\t\n",
+				pos.ID, strings.TrimSuffix(pos.Symbol, "USDT"), pos.BuyPrice, pos.Quantity, pos.InvestedAmount,
+				pos.TargetSellPrice, currentPrice, pnl, pnlPercent, formatAge(time.Since(pos.BuyTime)))
+		}
+		w.Flush()
+	}
+
+	fmt.Println()
+	fmt.Printf("Open positions:    %d\n", len(bot.Positions))
+	fmt.Printf("Available budget:  %.2f USDT\n", bot.AvailableBudget)
+	fmt.Printf("Portfolio value:   %.2f USDT\n", bot.getCurrentPortfolioValue())
+	fmt.Printf("Realized P/L:      %+.2f USDT\n", bot.realizedPnL())
+	fmt.Printf("Unrealized P/L:    %+.2f USDT\n", bot.unrealizedPnL())
+}
+
+// ShowHistory prints completed trades from the persisted bot state, oldest sale first, with a performance summary.
+// --since YYYY-MM-DD limits the list and the summary to trades sold on or after that date.
+func ShowHistory(args []string) {
+	var since time.Time
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--since":
+			if i+1 >= len(args) {
+				fmt.Println("Usage: ./trading-bot history [--since YYYY-MM-DD]")
+				os.Exit(1)
+			}
+			i++
+			date, err := time.ParseInLocation("2006-01-02", args[i], time.Local)
+			if err != nil {
+				fmt.Printf("Invalid date %q - expected YYYY-MM-DD\n", args[i])
+				os.Exit(1)
+			}
+			since = date
+		default:
+			fmt.Printf("Unknown option %q\n", args[i])
+			fmt.Println("Usage: ./trading-bot history [--since YYYY-MM-DD]")
+			os.Exit(1)
+		}
+	}
+
+	path := stateFilePath()
+	bot := &TradingBot{}
+	if err := bot.LoadState(path); err != nil {
+		log.Fatalf("ERROR: Could not load state from %s: %v", path, err)
+	}
+
+	trades := make([]CompletedTrade, 0, len(bot.CompletedTrades))
+	for _, trade := range bot.CompletedTrades {
+		if !trade.SellTime.Before(since) {
+			trades = append(trades, trade)
+		}
+	}
+	slices.SortStableFunc(trades, func(a, b CompletedTrade) int { return a.SellTime.Compare(b.SellTime) })
+
+	fmt.Printf("=== Trade History (state file: %s) ===\n", path)
+	if !since.IsZero() {
+		fmt.Printf("Sold since %s\n", since.Format("2006-01-02"))
+	}
+	fmt.Println()
+
+	if len(trades) == 0 {
+		fmt.Println("No completed trades")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ID\tSYMBOL\tSOLD\tBUY PRICE\tSELL PRICE\tQUANTITY\tINVESTED\tFEES\tP/L USDT\tP/L %\tHELD\t")
+	for _, trade := range trades {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.6f\t%.6f\t%.6f\t%.2f\t%.2f\t%+.2f\t%+.2f%%\t%s\t\n",
+			trade.ID, strings.TrimSuffix(trade.Symbol, "USDT"), trade.SellTime.Local().Format("2006-01-02 15:04"),
+			trade.BuyPrice, trade.SellPrice, trade.Quantity, trade.InvestedAmount, trade.Fees,
+			trade.Profit, trade.ProfitPercent, formatAge(trade.HoldDuration))
+	}
+	w.Flush()
+
+	// The saved stats cover every trade; a date filter needs them rebuilt from the trades shown
+	stats := bot.Stats
+	if !since.IsZero() {
+		summary := &TradingBot{}
+		for _, trade := range trades {
+			summary.updateStats(trade)
+		}
+		stats = summary.Stats
+	}
+
+	fmt.Println()
+	fmt.Printf("Trades:            %d (%d won / %d lost)\n", stats.TotalTrades, stats.WinningTrades, stats.LosingTrades)
+	fmt.Printf("Win rate:          %.1f%%\n", stats.WinRate)
+	fmt.Printf("Net profit:        %+.2f USDT (fees %.2f USDT)\n", stats.NetProfit, stats.TotalFees)
+	fmt.Printf("Average hold:      %s\n", formatAge(stats.AverageHoldTime))
+}
+
+// ShowOpenOrders prints every open order on the Binance account, independent of the local state file
+func ShowOpenOrders() {
+	client := newHTTPClient()
+	bot := &TradingBot{Binance: NewBinanceClient(BinanceConfig{
+		APIKey:    os.Getenv("BINANCE_API_KEY"),
+		SecretKey: os.Getenv("BINANCE_SECRET_KEY"),
+		BaseURL:   binanceBaseURL(),
+	}, client), httpClient: client}
+
+	if err := syncServerTime(bot.httpClient, bot.Binance.Config.BaseURL); err != nil {
+		log.Printf("WARNING: Could not sync with Binance server time, using local clock: %v", err)
+	}
+
+	orders, err := bot.Binance.OpenOrders()
+	if err != nil {
+		log.Fatalf("ERROR: Could not fetch open orders: %v", err)
+	}
+
+	fmt.Printf("=== Open Binance Orders (%s) ===\n\n", bot.Binance.Config.BaseURL)
+
+	if len(orders) == 0 {
+		fmt.Println("No open orders")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ORDER ID\tSYMBOL\tSIDE\tTYPE\tPRICE\tQUANTITY\tFILLED\tSTATUS\t")
+	for _, order := range orders {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			order.OrderID, order.Symbol, order.Side, order.Type, order.Price, order.OrigQty, order.ExecutedQty, order.Status)
+	}
+	w.Flush()
+
+	fmt.Printf("\nOpen orders: %d\n", len(orders))
+}
+
+// CancelOrder cancels a single open order on Binance, e.g. a limit sell stuck at its target
+func CancelOrder(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: ./trading-bot cancel <orderId> <symbol>")
+		fmt.Println("Example: ./trading-bot cancel 123456789 ADAUSDT")
+		os.Exit(1)
+	}
+
+	orderID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || orderID <= 0 {
+		fmt.Printf("Invalid order ID %q - expected a positive number\n", args[0])
+		os.Exit(1)
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(args[1]))
+	if !strings.HasSuffix(symbol, "USDT") {
+		symbol += "USDT"
+	}
+
+	client := newHTTPClient()
+	bot := &TradingBot{Binance: NewBinanceClient(BinanceConfig{
+		APIKey:    os.Getenv("BINANCE_API_KEY"),
+		SecretKey: os.Getenv("BINANCE_SECRET_KEY"),
+		BaseURL:   binanceBaseURL(),
+	}, client), httpClient: client}
+
+	if err := syncServerTime(bot.httpClient, bot.Binance.Config.BaseURL); err != nil {
+		log.Printf("WARNING: Could not sync with Binance server time, using local clock: %v", err)
+	}
+
+	order, err := bot.cancelOrder(symbol, orderID)
+	if err != nil {
+		log.Fatalf("ERROR: Could not cancel order %d on %s: %v", orderID, symbol, err)
+	}
+
+	fmt.Printf("Cancelled order %d on %s\n", order.OrderID, order.Symbol)
+	fmt.Printf("  Side/type: %s %s\n", order.Side, order.Type)
+	fmt.Printf("  Price:     %s\n", order.Price)
+	fmt.Printf("  Quantity:  %s (filled %s)\n", order.OrigQty, order.ExecutedQty)
+	fmt.Printf("  Status:    %s\n", order.Status)
+	fmt.Println("The bot will release the position's sell order on its next reconcile.")
+}
+
+// SellPosition market-sells the free balance of an asset for an emergency exit, closing its
+// position in the saved state when one exists
+func SellPosition(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: ./trading-bot sell <symbol>")
+		fmt.Println("Example: ./trading-bot sell ADA")
+		os.Exit(1)
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(args[0]))
+	if !strings.HasSuffix(symbol, "USDT") {
+		symbol += "USDT"
+	}
+	baseAsset := strings.TrimSuffix(symbol, "USDT")
+
+	client := newHTTPClient()
+	bot := &TradingBot{
+		Binance: NewBinanceClient(BinanceConfig{
+			APIKey:    os.Getenv("BINANCE_API_KEY"),
+			SecretKey: os.Getenv("BINANCE_SECRET_KEY"),
+			BaseURL:   binanceBaseURL(),
+		}, client),
+		httpClient:      client,
+		ExchangeInfoTTL: defaultExchangeInfoTTL,
+		TakerFeePercent: getEnvFloat("TAKER_FEE_PCT", 0.1),
+		MakerFeePercent: getEnvFloat("MAKER_FEE_PCT", 0.1),
+		DryRun:          getEnvBool("DRY_RUN", false),
+	}
+
+	// Saved state is optional: without it we can still sell, just not report P/L
+	path := stateFilePath()
+	hasState := true
+	if err := bot.LoadState(path); err != nil {
+		hasState = false
+		if !os.IsNotExist(err) {
+			log.Printf("WARNING: Could not load state from %s, P/L will not be reported: %v", path, err)
+		}
+	}
+
+	var position *TradingPosition
+	for i := range bot.Positions {
+		if bot.Positions[i].Symbol == symbol {
+			position = &bot.Positions[i]
+			break
+		}
+	}
+
+	if !bot.DryRun {
+		if err := syncServerTime(bot.httpClient, bot.Binance.Config.BaseURL); err != nil {
+			log.Printf("WARNING: Could not sync with Binance server time, using local clock: %v", err)
+		}
+	}
+
+	// A resting limit sell locks the balance, so it has to go first
+	if position != nil && position.HasActiveSellOrder {
+		if err := bot.cancelSellOrders(position); err != nil {
+			log.Fatalf("ERROR: %v", err)
+		}
+		fmt.Printf("Cancelled resting sell orders for %s\n", baseAsset)
+
+		// The resting sell may have sold the whole position just before it was cancelled; any other
+		// free balance is still sold below, just without P/L
+		if position.Quantity == 0 {
+			fmt.Printf("The %s sell order filled before it was cancelled - position closed\n", baseAsset)
+			bot.removePosition(position.ID)
+			position = nil
+			if hasState {
+				if err := bot.SaveState(path); err != nil {
+					log.Printf("WARNING: Failed to update state file %s: %v", path, err)
+				}
+			}
+			if bot.DryRun {
+				return
+			}
+		}
+	}
+
+	var quantity float64
+	if bot.DryRun {
+		// Simulated positions have no exchange balance to look up
+		if position == nil {
+			log.Fatalf("ERROR: No dry run position for %s in %s", baseAsset, path)
+		}
+		quantity = position.Quantity
+	} else {
+		free, err := bot.Binance.AccountBalance(baseAsset)
+		if err != nil {
+			log.Fatalf("ERROR: Could not fetch %s balance: %v", baseAsset, err)
+		}
+		quantity = free
+	}
+
+	quantity = bot.sellQuantity(symbol, quantity)
+	if quantity <= 0 {
+		log.Fatalf("ERROR: No sellable %s balance", baseAsset)
+	}
+
+	fmt.Printf("Market-selling %.8f %s...\n", quantity, baseAsset)
+	orderResp, err := bot.executeSellOrder(symbol, quantity)
+	if err != nil {
+		log.Fatalf("ERROR: Sell order failed: %v", err)
+	}
+
+	executedQty, _ := strconv.ParseFloat(orderResp.ExecutedQty, 64)
+	quoteQty, _ := strconv.ParseFloat(orderResp.QuoteQty, 64)
+	avgPrice := 0.0
+	if executedQty > 0 {
+		avgPrice = quoteQty / executedQty
+	}
+	avgPrice = averageFillPrice(orderResp, avgPrice)
+	_, sellFee, bnbFee := fillCommissions(orderResp.Fills, baseAsset)
+
+	fmt.Printf("SOLD: order %d %s - %.8f %s at $%.6f avg (%s USDT)\n",
+		orderResp.OrderID, orderResp.Status, executedQty, baseAsset, avgPrice, orderResp.QuoteQty)
+	for _, fill := range orderResp.Fills {
+		fmt.Printf("  fill: %s @ %s (commission %s %s)\n", fill.Qty, fill.Price, fill.Commission, fill.CommissionAsset)
+	}
+
+	if position == nil {
+		fmt.Printf("No saved position for %s - P/L not available\n", baseAsset)
+		return
+	}
+
+	// Close the tracked position; extra free balance beyond it was not bought by the bot. A partial fill
+	// only closes its share of the cost, and the unsold rest stays tracked.
+	sold, rest := splitPosition(*position, math.Min(executedQty, position.Quantity))
+	sold.BNBFeesPaid += bnbFee
+	trade := bot.recordCompletedTrade(sold, avgPrice, sellFee+bot.bnbFeeValue(bnbFee))
+
+	if rest.Quantity > position.Quantity*1e-6 {
+		positions := bot.getPositionsSnapshot()
+		for i := range positions {
+			if positions[i].ID == rest.ID {
+				positions[i] = rest
+			}
+		}
+		bot.setPositions(positions)
+		fmt.Printf("Only %.8f of %.8f %s sold - the remaining %.8f stay tracked\n",
+			sold.Quantity, position.Quantity, baseAsset, rest.Quantity)
+	} else {
+		bot.removePosition(position.ID)
+	}
+
+	fmt.Printf("Bought at $%.6f -> sold at $%.6f: P/L %+.2f USDT (%+.2f%%)\n",
+		trade.BuyPrice, trade.SellPrice, trade.Profit, trade.ProfitPercent)
+
+	if hasState {
+		if err := bot.SaveState(path); err != nil {
+			log.Printf("WARNING: Sold, but failed to update state file %s: %v", path, err)
+		}
+	}
+}
+
+// CleanupDust lists leftover base-asset balances worth less than their symbol's minimum order, which
+// can't be sold normally. With --convert they are swapped for BNB through Binance's dust endpoint;
+// otherwise they are only listed for manual handling.
+func CleanupDust(args []string) {
+	convert := false
+	for _, arg := range args {
+		switch arg {
+		case "--convert":
+			convert = true
+		default:
+			fmt.Println("Usage: ./trading-bot dust [--convert]")
+			os.Exit(1)
+		}
+	}
+
+	if getEnvBool("DRY_RUN", false) {
+		fmt.Println("ERROR: Dust cleanup works on the real Binance account balances and is not available in DRY_RUN mode")
+		os.Exit(1)
+	}
+
+	client := newHTTPClient()
+	bot := &TradingBot{
+		Binance: NewBinanceClient(BinanceConfig{
+			APIKey:    os.Getenv("BINANCE_API_KEY"),
+			SecretKey: os.Getenv("BINANCE_SECRET_KEY"),
+			BaseURL:   binanceBaseURL(),
+		}, client),
+		httpClient:      client,
+		ExchangeInfoTTL: defaultExchangeInfoTTL,
+	}
+
+	// Balances behind open positions are the bot's, not dust, even when small
+	held := make(map[string]bool)
+	if err := bot.LoadState(stateFilePath()); err == nil {
+		for _, pos := range bot.Positions {
+			held[strings.TrimSuffix(pos.Symbol, "USDT")] = true
+		}
+	}
+
+	if err := syncServerTime(bot.httpClient, bot.Binance.Config.BaseURL); err != nil {
+		log.Printf("WARNING: Could not sync with Binance server time, using local clock: %v", err)
+	}
+	if err := bot.prefetchExchangeInfo(); err != nil {
+		log.Printf("WARNING: Could not prefetch exchange info: %v", err)
+	}
+
+	accountInfo, err := bot.Binance.AccountInfo()
+	if err != nil {
+		log.Fatalf("ERROR: Could not fetch account balances: %v", err)
+	}
+
+	fmt.Printf("=== Dust Balances (%s) ===\n\n", bot.Binance.Config.BaseURL)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ASSET\tFREE\tVALUE (USDT)\tMIN ORDER\tNOTE")
+	var dust []string
+	for _, balance := range accountInfo.Balances {
+		free, _ := strconv.ParseFloat(balance.Free, 64)
+		// BNB is what dust converts into, and USDT is the trading budget
+		if free <= 0 || balance.Asset == "USDT" || balance.Asset == "BNB" {
+			continue
+		}
+
+		symbol := balance.Asset + "USDT"
+		price, err := bot.getTickerPrice(symbol)
+		if err != nil {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\tno USDT price, handle manually\n", balance.Asset, balance.Free)
+			continue
+		}
+
+		minNotional := 0.0
+		if filters, err := bot.getSymbolFilters(symbol); err == nil {
+			minNotional, _ = strconv.ParseFloat(filters.MinNotional, 64)
+		}
+
+		value := free * price
+		if value >= minNotional {
+			continue
+		}
+
+		note := "dust"
+		if held[balance.Asset] {
+			note = "open position in state, skipped"
+		} else {
+			dust = append(dust, balance.Asset)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.4f\t%.2f\t%s\n", balance.Asset, balance.Free, value, minNotional, note)
+	}
+	w.Flush()
+
+	if len(dust) == 0 {
+		fmt.Println("\nNo dust balances to convert")
+		return
+	}
+
+	if !convert {
+		fmt.Printf("\n%d dust balance(s): %s\n", len(dust), strings.Join(dust, ", "))
+		fmt.Println("Run './trading-bot dust --convert' to convert them to BNB, or sell them manually on Binance")
+		return
+	}
+
+	fmt.Printf("\nConverting %s to BNB...\n", strings.Join(dust, ", "))
+	result, err := bot.Binance.DustTransfer(dust)
+	if err != nil {
+		log.Fatalf("ERROR: Dust conversion failed: %v", err)
+	}
+	for _, transfer := range result.TransferResult {
+		fmt.Printf("  %s %s -> %s BNB (fee %s BNB)\n", transfer.Amount, transfer.FromAsset,
+			transfer.TransferedAmount, transfer.ServiceChargeAmount)
+	}
+	fmt.Printf("SUCCESS: Converted dust to %s BNB (fees %s BNB)\n", result.TotalTransfered, result.TotalServiceCharge)
+}
+
+// ShowBalance prints the free USDT balance and every non-zero asset balance on the Binance account
+func ShowBalance() {
+	apiKey := os.Getenv("BINANCE_API_KEY")
+	secretKey := os.Getenv("BINANCE_SECRET_KEY")
+	baseURL := binanceBaseURL()
+	client := newHTTPClient()
+	binance := NewBinanceClient(BinanceConfig{APIKey: apiKey, SecretKey: secretKey, BaseURL: baseURL}, client)
+
+	if apiKey == "" || secretKey == "" {
+		fmt.Println("ERROR: BINANCE_API_KEY and BINANCE_SECRET_KEY must be set to check balances")
+		os.Exit(1)
+	}
+
+	if err := syncServerTime(client, baseURL); err != nil {
+		log.Printf("WARNING: Could not sync with Binance server time, using local clock: %v", err)
+	}
+
+	usdtBalance, err := getRealUSDTBalance(binance)
+	if err != nil {
+		log.Fatalf("ERROR: Could not fetch USDT balance: %v", err)
+	}
+
+	accountInfo, err := binance.AccountInfo()
+	if err != nil {
+		log.Fatalf("ERROR: Could not fetch account balances: %v", err)
+	}
+
+	fmt.Printf("=== Binance Balances (%s) ===\n\n", baseURL)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ASSET\tFREE\tLOCKED\t")
+	assets := 0
+	for _, balance := range accountInfo.Balances {
+		free, _ := strconv.ParseFloat(balance.Free, 64)
+		locked, _ := strconv.ParseFloat(balance.Locked, 64)
+		if free == 0 && locked == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", balance.Asset, balance.Free, balance.Locked)
+		assets++
+	}
+	w.Flush()
+
+	if assets == 0 {
+		fmt.Println("No non-zero balances")
+	}
+	fmt.Printf("\nFree USDT (trading budget): %.2f USDT\n", usdtBalance)
+}
+
+// TestCMC fetches the CoinMarketCap watchlist once and prints each coin's signal, to check the CMC key
+// and connectivity without starting the trading loop
+func TestCMC() {
+	if os.Getenv("COIN_MARKET_CAP_API_KEY") == "" {
+		fmt.Println("ERROR: COIN_MARKET_CAP_API_KEY must be set to test CoinMarketCap")
+		os.Exit(1)
+	}
+
+	// Nothing is traded, so reuse the dry run path to build the bot without Binance keys
+	os.Setenv("DRY_RUN", "true")
+	bot, err := NewTradingBot(0)
+	if err != nil {
+		log.Fatalf("ERROR: Failed to initialize bot config: %v", err)
+	}
+
+	coins, err := bot.fetchTop20CoinsFromCMC()
+	if err != nil {
+		log.Fatalf("ERROR: CoinMarketCap request failed: %v", err)
+	}
+
+	fmt.Printf("\n=== CoinMarketCap watchlist (%d coins, buy band %s) ===\n\n", len(coins), bot.dropBandLabel())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COIN\tPRICE\t24H CHANGE\tSIGNAL")
+	for _, coin := range coins {
+		fmt.Fprintf(w, "%s\t$%.6f\t%+.2f%%\t%s\n", strings.TrimSuffix(coin.Symbol, "USDT"),
+			coin.LastPrice, coin.PriceChangePercent, dropSignalLabel(bot.classifyDropSignal(coin.PriceChangePercent)))
+	}
+	w.Flush()
+
+	fmt.Println("\nSUCCESS: CoinMarketCap key and connectivity are working")
+}
+
+// dropSignalLabel describes a signal for the test-cmc table
+func dropSignalLabel(signal Signal) string {
+	switch signal {
+	case SignalDanger:
+		return "DANGER (past safety limit)"
+	case SignalRisky:
+		return "RISKY (past buy band)"
+	default:
+		return signal.String()
+	}
+}
+
+// TestBinance runs a pass/fail checklist of the common Binance setup problems: connectivity, clock skew,
+// key validity and trading permission
+func TestBinance() {
+	apiKey := os.Getenv("BINANCE_API_KEY")
+	secretKey := os.Getenv("BINANCE_SECRET_KEY")
+	baseURL := binanceBaseURL()
+	client := newHTTPClient()
+	binance := NewBinanceClient(BinanceConfig{APIKey: apiKey, SecretKey: secretKey, BaseURL: baseURL}, client)
+
+	fmt.Printf("=== Binance checks (%s) ===\n\n", baseURL)
+	failed := 0
+	check := func(name string, err error) bool {
+		if err != nil {
+			fmt.Printf("[FAIL] %s: %v\n", name, err)
+			failed++
+			return false
+		}
+		fmt.Printf("[PASS] %s\n", name)
+		return true
+	}
+
+	if !check("Connectivity (/api/v3/ping)", pingBinance(client, baseURL)) {
+		fmt.Println("\nCannot reach Binance - check the network, BINANCE_BASE_URL and any firewall or region block")
+		os.Exit(1)
+	}
+
+	if check("Server time (/api/v3/time)", syncServerTime(client, baseURL)) {
+		offset := serverTimeOffsetMs.Load()
+		var skewErr error
+		if offset > int64(recvWindowMs()) || -offset > int64(recvWindowMs()) {
+			skewErr = fmt.Errorf("local clock is %dms off, beyond the %dms recvWindow - enable NTP", offset, recvWindowMs())
+		}
+		check(fmt.Sprintf("Clock skew (%+dms, recvWindow %dms)", offset, recvWindowMs()), skewErr)
+	}
+
+	if !check("API keys present and plausible", validateBinanceKeys(apiKey, secretKey)) {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+
+	accountInfo, err := binance.AccountInfo()
+	if err != nil {
+		// pingBinanceAuth explains the common auth error codes
+		check("Account access (signed /api/v3/account)", pingBinanceAuth(binance))
+	} else {
+		check("Account access (signed /api/v3/account)", nil)
+
+		var tradeErr error
+		if !accountInfo.CanTrade {
+			tradeErr = fmt.Errorf("account reports canTrade=false - enable spot trading for this API key")
+		}
+		check("Trading permission (canTrade)", tradeErr)
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed - fix them before running ./trading-bot start\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("\nAll checks passed - the bot can trade with these keys")
+}
+
+// formatAge formats a duration as days/hours/minutes for tables
+func formatAge(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd%02dh", days, hours)
+	}
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}
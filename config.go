@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// getEnvInt reads an integer setting from the environment, falling back to the default when unset or invalid
+func getEnvInt(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("WARNING: Invalid %s=%q, using default %d", key, raw, fallback)
+		return fallback
+	}
+
+	return value
+}
+
+// getEnvFloat reads a decimal setting from the environment, falling back to the default when unset or invalid
+func getEnvFloat(key string, fallback float64) float64 {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("WARNING: Invalid %s=%q, using default %.2f", key, raw, fallback)
+		return fallback
+	}
+
+	return value
+}
+
+// getEnvBool reads a true/false setting from the environment, falling back to the default when unset or invalid
+func getEnvBool(key string, fallback bool) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("WARNING: Invalid %s=%q, using default %t", key, raw, fallback)
+		return fallback
+	}
+
+	return value
+}
+
+// getEnvSymbolSet reads a comma-separated list of asset symbols (e.g. "USDT,USDC") as an upper-case set,
+// falling back to the default list when unset
+func getEnvSymbolSet(key string, fallback []string) map[string]bool {
+	symbols := fallback
+	if raw := strings.TrimSpace(os.Getenv(key)); raw != "" {
+		symbols = strings.Split(raw, ",")
+	}
+
+	set := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			set[symbol] = true
+		}
+	}
+
+	return set
+}
+
+// getEnvBaseAssets reads a comma-separated list of coins as a set of base assets, accepting either
+// the asset (DOGE) or its USDT pair (DOGEUSDT)
+func getEnvBaseAssets(key string) map[string]bool {
+	assets := make(map[string]bool)
+	for symbol := range getEnvSymbolSet(key, nil) {
+		if len(symbol) > len("USDT") {
+			symbol = strings.TrimSuffix(symbol, "USDT")
+		}
+		assets[symbol] = true
+	}
+	return assets
+}
+
+// sellTranche is one step of scaling out of a position: sell Fraction of the quantity at ProfitPercent net profit
+type sellTranche struct {
+	ProfitPercent float64
+	Fraction      float64
+}
+
+// parseSellTranches parses SELL_TRANCHES as comma-separated percent:fraction pairs, e.g. "3:0.5,6:0.5" sells
+// half at +3% and half at +6% net profit. Fractions must add up to 1; tranches are ordered by price.
+func parseSellTranches(raw string) ([]sellTranche, error) {
+	var tranches []sellTranche
+	total := 0.0
+	for _, pair := range strings.Split(raw, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		percentStr, fractionStr, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("tranche %q is not percent:fraction", pair)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSpace(percentStr), 64)
+		if err != nil || percent <= 0 {
+			return nil, fmt.Errorf("tranche %q needs a positive profit percent", pair)
+		}
+		fraction, err := strconv.ParseFloat(strings.TrimSpace(fractionStr), 64)
+		if err != nil || fraction <= 0 || fraction > 1 {
+			return nil, fmt.Errorf("tranche %q needs a fraction between 0 and 1", pair)
+		}
+
+		tranches = append(tranches, sellTranche{ProfitPercent: percent, Fraction: fraction})
+		total += fraction
+	}
+
+	if len(tranches) == 0 {
+		return nil, nil
+	}
+	if math.Abs(total-1) > 1e-6 {
+		return nil, fmt.Errorf("fractions add up to %g, not 1", total)
+	}
+
+	sort.Slice(tranches, func(i, j int) bool { return tranches[i].ProfitPercent < tranches[j].ProfitPercent })
+	return tranches, nil
+}
+
+// Plausible Binance API key/secret lengths; real HMAC keys are 64 characters
+const (
+	minBinanceKeyLength = 32
+	maxBinanceKeyLength = 128
+)
+
+// placeholderKeyMarkers are fragments of the example values people tend to leave in .env
+var placeholderKeyMarkers = []string{"your_", "_here", "placeholder", "changeme", "change_me", "example", "xxxx", "<", ">"}
+
+// validateBinanceKeys rejects empty, placeholder and implausibly sized API credentials before any request is made
+func validateBinanceKeys(apiKey, secretKey string) error {
+	for _, key := range []struct{ name, value string }{
+		{"BINANCE_API_KEY", apiKey},
+		{"BINANCE_SECRET_KEY", secretKey},
+	} {
+		if key.value == "" {
+			return fmt.Errorf("%s is not set", key.name)
+		}
+
+		lower := strings.ToLower(key.value)
+		for _, marker := range placeholderKeyMarkers {
+			if strings.Contains(lower, marker) {
+				// Never echo the value: a real secret could contain a marker and would end up in the logs
+				return fmt.Errorf("%s looks like a placeholder (%d characters) - paste the real key from your Binance API management page",
+					key.name, len(key.value))
+			}
+		}
+
+		if strings.ContainsAny(key.value, " \t\"'") {
+			return fmt.Errorf("%s contains spaces or quotes - check for copy/paste mistakes in .env", key.name)
+		}
+
+		if len(key.value) < minBinanceKeyLength || len(key.value) > maxBinanceKeyLength {
+			return fmt.Errorf("%s has an implausible length (%d characters, Binance keys are 64) - check it was copied completely",
+				key.name, len(key.value))
+		}
+	}
+
+	return nil
+}
+
+// secretFileKeys are the credentials that can be read from a file named by <KEY>_FILE instead,
+// as with Docker and systemd secrets
+var secretFileKeys = []string{"BINANCE_API_KEY", "BINANCE_SECRET_KEY", "COIN_MARKET_CAP_API_KEY"}
+
+// loadSecretFiles sets each credential whose <KEY>_FILE variable is set to the trimmed contents of that file.
+// The file takes precedence over the credential itself, so the rest of the bot keeps reading it through os.Getenv.
+func loadSecretFiles() error {
+	for _, key := range secretFileKeys {
+		path := strings.TrimSpace(os.Getenv(key + "_FILE"))
+		if path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s_FILE: %v", key, err)
+		}
+		value := strings.TrimSpace(string(content))
+		if value == "" {
+			return fmt.Errorf("%s_FILE %s is empty", key, path)
+		}
+
+		if os.Getenv(key) != "" {
+			log.Printf("WARNING: Both %s and %s_FILE are set, using the file", key, key)
+		}
+		os.Setenv(key, value)
+	}
+
+	return nil
+}
+
+// defaultConfigFile is read at startup when CONFIG_FILE is not set; it is optional
+const defaultConfigFile = "config.json"
+
+// secretConfigKeys stay in .env or the environment; a config file is easy to commit or share by accident
+var secretConfigKeys = map[string]bool{
+	"BINANCE_API_KEY":         true,
+	"BINANCE_SECRET_KEY":      true,
+	"COIN_MARKET_CAP_API_KEY": true,
+	"TELEGRAM_BOT_TOKEN":      true,
+	"DISCORD_WEBHOOK_URL":     true,
+}
+
+// loadConfigFile applies settings from a JSON config file whose keys are the environment variable names,
+// e.g. {"BUY_DROP_MIN": -5, "CYCLE_INTERVAL_MINUTES": 30, "STABLECOIN_SYMBOLS": ["USDT", "USDC"]}.
+//
+// Precedence, highest first: the process environment, then .env (loaded into the environment before this
+// runs), then the config file, then the built-in defaults. A file value is only used when the variable is
+// unset or empty, so the rest of the bot keeps reading every setting through getEnv*. A missing file is
+// not an error.
+func loadConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading config file: %v", err)
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+
+	applied := 0
+	for rawKey, rawValue := range settings {
+		key := strings.ToUpper(strings.TrimSpace(rawKey))
+		if secretConfigKeys[key] {
+			log.Printf("WARNING: Ignoring %s in %s - keep credentials in .env", key, path)
+			continue
+		}
+		if strings.TrimSpace(os.Getenv(key)) != "" {
+			continue
+		}
+
+		value, err := configValueString(rawValue)
+		if err != nil {
+			log.Printf("WARNING: Ignoring %s in %s: %v", key, path, err)
+			continue
+		}
+		os.Setenv(key, value)
+		applied++
+	}
+
+	fmt.Printf("Loaded %d setting(s) from %s (environment variables take precedence)\n", applied, path)
+	return nil
+}
+
+// configValueString converts a JSON config value to the string form the environment would hold.
+// Lists become comma-separated, matching settings like STABLECOIN_SYMBOLS.
+func configValueString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configValueString(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v (use a string, number, boolean or list)", value)
+	}
+}
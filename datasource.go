@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Market data providers selectable with DATA_SOURCE
+const (
+	dataSourceCMC       = "cmc"
+	dataSourceBinance   = "binance"
+	dataSourceCoinGecko = "coingecko"
+)
+
+// CoinGecko free markets endpoint by market cap; per_page is filled in from CMC_FETCH_LIMIT, which leaves
+// room for stablecoins and untradeable coins to be filtered out. CoinGecko returns at most 250 per page.
+const (
+	coinGeckoMarketsURL = "https://api.coingecko.com/api/v3/coins/markets?vs_currency=usd&order=market_cap_desc&per_page=%d&page=1"
+	coinGeckoMaxPerPage = 250
+)
+
+// Retry policy for CoinGecko's free tier, which rate limits aggressively: 3 attempts, backing off 2s then 4s
+const (
+	coinGeckoMaxAttempts = 3
+	coinGeckoRetryBase   = 2 * time.Second
+)
+
+// CoinGeckoMarket is one coin from the CoinGecko /coins/markets response
+type CoinGeckoMarket struct {
+	ID                       string  `json:"id"`
+	Symbol                   string  `json:"symbol"`
+	Name                     string  `json:"name"`
+	CurrentPrice             float64 `json:"current_price"`
+	MarketCap                float64 `json:"market_cap"`
+	TotalVolume              float64 `json:"total_volume"`
+	PriceChangePercentage24h float64 `json:"price_change_percentage_24h"`
+}
+
+// defaultWatchlistSize is how many coins each provider returns for analysis unless WATCHLIST_SIZE overrides it
+const defaultWatchlistSize = 20
+
+// parseDataSource normalizes a DATA_SOURCE value, reporting false for unknown providers
+func parseDataSource(raw string) (string, bool) {
+	switch source := strings.ToLower(strings.TrimSpace(raw)); source {
+	case "", dataSourceCMC, "coinmarketcap":
+		return dataSourceCMC, true
+	case dataSourceBinance:
+		return dataSourceBinance, true
+	case dataSourceCoinGecko:
+		return dataSourceCoinGecko, true
+	default:
+		return dataSourceCMC, false
+	}
+}
+
+// dataSourceLabel names the configured market data provider for console output
+func (bot *TradingBot) dataSourceLabel() string {
+	switch bot.DataSource {
+	case dataSourceBinance:
+		return fmt.Sprintf("Binance 24hr ticker (top %d USDT pairs by volume)", bot.WatchlistSize)
+	case dataSourceCoinGecko:
+		return fmt.Sprintf("CoinGecko API (Top %d by market cap, excluding stablecoins)", bot.WatchlistSize)
+	default:
+		return fmt.Sprintf("CoinMarketCap API (Top %d, excluding stablecoins)", bot.WatchlistSize)
+	}
+}
+
+// fetchWatchList fetches the top coins from the configured data source
+func (bot *TradingBot) fetchWatchList() ([]OptimizedTicker, error) {
+	switch bot.DataSource {
+	case dataSourceBinance:
+		return bot.fetchTop20FromBinance()
+	case dataSourceCoinGecko:
+		return bot.fetchTop20FromCoinGecko()
+	default:
+		return bot.fetchTop20CoinsFromCMC()
+	}
+}
+
+// fetchTop20FromBinance builds the watchlist from Binance's 24hr ticker: the top USDT pairs by quote volume,
+// excluding stablecoins. Needs no API key.
+func (bot *TradingBot) fetchTop20FromBinance() ([]OptimizedTicker, error) {
+	logInfo.Printf("Fetching top %d USDT pairs by volume from Binance 24hr ticker...\n", bot.WatchlistSize)
+
+	resp, err := bot.httpClient.Get(bot.Binance.Config.BaseURL + "/api/v3/ticker/24hr")
+	if err != nil {
+		return nil, fmt.Errorf("error getting 24hr tickers: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading 24hr ticker response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("24hr ticker request failed: %w", parseBinanceError(resp.StatusCode, body))
+	}
+
+	var tickers []Ticker24hr
+	if err := json.Unmarshal(body, &tickers); err != nil {
+		return nil, fmt.Errorf("error parsing 24hr tickers: %v", err)
+	}
+
+	// The 24hr ticker also lists delisted and halted pairs, so check against exchange info
+	tradeable := bot.tradeableSymbols()
+
+	candidates := make([]OptimizedTicker, 0, len(tickers))
+	for _, ticker := range tickers {
+		if !strings.HasSuffix(ticker.Symbol, "USDT") || (tradeable != nil && !tradeable[ticker.Symbol]) {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		change24h, _ := strconv.ParseFloat(ticker.PriceChangePercent, 64)
+		quoteVolume, _ := strconv.ParseFloat(ticker.QuoteVolume, 64)
+
+		candidates = append(candidates, OptimizedTicker{
+			Symbol:             ticker.Symbol,
+			LastPrice:          price,
+			PriceChangePercent: change24h,
+			Volume24h:          quoteVolume,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Volume24h > candidates[j].Volume24h
+	})
+
+	logInfo.Println("\n=== FILTERING BINANCE USDT PAIRS BY VOLUME ===")
+
+	topCoins := make([]OptimizedTicker, 0, bot.WatchlistSize)
+	for _, coin := range candidates {
+		coinName := strings.TrimSuffix(coin.Symbol, "USDT")
+		if len(topCoins) >= bot.WatchlistSize {
+			bot.explain(coin.Symbol, ReasonOutsideWatchlist, "watchlist already has %d coins", bot.WatchlistSize)
+			continue
+		}
+
+		if stable, why := bot.isStablecoin(coinName, coin.LastPrice, coin.PriceChangePercent); stable {
+			logInfo.Printf("SKIP: %s: stablecoin (%s)\n", coinName, why)
+			bot.explain(coin.Symbol, ReasonStablecoin, "%s", why)
+			continue
+		}
+
+		// Sorted by volume, so every remaining pair is below the minimum too
+		if bot.lowVolume(coin.Symbol, coin.Volume24h) {
+			break
+		}
+
+		topCoins = append(topCoins, coin)
+		bot.logWatchlistCoin(coinName, coin.LastPrice, coin.PriceChangePercent)
+	}
+
+	bot.printWatchlistSummary(topCoins, "Binance 24hr ticker (no API key needed)")
+	return topCoins, nil
+}
+
+// fetchTop20FromCoinGecko builds the watchlist from CoinGecko's free markets endpoint: the top coins
+// by market cap that are not stablecoins and trade against USDT on Binance. Needs no API key.
+func (bot *TradingBot) fetchTop20FromCoinGecko() ([]OptimizedTicker, error) {
+	logInfo.Printf("Fetching top %d non-stablecoin coins from CoinGecko API...\n", bot.WatchlistSize)
+
+	perPage := min(bot.CMCFetchLimit, coinGeckoMaxPerPage)
+	markets, err := fetchCoinGeckoMarkets(bot.httpClient, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only keep coins with a USDT pair that is actually trading on Binance
+	tradeable := bot.tradeableSymbols()
+
+	logInfo.Printf("\n=== FILTERING COINGECKO TOP %d FOR TRADING ===\n", len(markets))
+
+	topCoins := make([]OptimizedTicker, 0, bot.WatchlistSize)
+	for _, market := range markets {
+		coinName := strings.ToUpper(market.Symbol)
+		symbol := coinName + "USDT"
+		if len(topCoins) >= bot.WatchlistSize {
+			bot.explain(symbol, ReasonOutsideWatchlist, "watchlist already has %d coins", bot.WatchlistSize)
+			continue
+		}
+
+		if stable, why := bot.isStablecoin(coinName, market.CurrentPrice, market.PriceChangePercentage24h); stable {
+			logInfo.Printf("SKIP: %s: stablecoin (%s)\n", coinName, why)
+			bot.explain(symbol, ReasonStablecoin, "%s", why)
+			continue
+		}
+
+		if bot.lowVolume(symbol, market.TotalVolume) {
+			continue
+		}
+
+		if tradeable != nil && !tradeable[symbol] {
+			logInfo.Printf("SKIP: %s: no tradeable %s pair on Binance\n", coinName, symbol)
+			bot.explain(symbol, ReasonNotOnBinance, "no trading %s pair in exchangeInfo", symbol)
+			continue
+		}
+
+		topCoins = append(topCoins, OptimizedTicker{
+			Symbol:             symbol,
+			LastPrice:          market.CurrentPrice,
+			PriceChangePercent: market.PriceChangePercentage24h,
+			Volume24h:          market.TotalVolume,
+		})
+		bot.logWatchlistCoin(coinName, market.CurrentPrice, market.PriceChangePercentage24h)
+	}
+
+	bot.printWatchlistSummary(topCoins, "CoinGecko API (free tier, no API key needed)")
+	return topCoins, nil
+}
+
+// fetchCoinGeckoMarkets fetches the top perPage CoinGecko markets, backing off and retrying on 429 and 5xx responses
+func fetchCoinGeckoMarkets(client *http.Client, perPage int) ([]CoinGeckoMarket, error) {
+
+	var lastErr error
+	for attempt := 1; attempt <= coinGeckoMaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(backoffDelay(coinGeckoRetryBase, attempt-1))
+		}
+
+		req, err := http.NewRequest("GET", fmt.Sprintf(coinGeckoMarketsURL, perPage), nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating CoinGecko request: %v", err)
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("error making CoinGecko request: %v", err)
+			log.Printf("WARNING: CoinGecko request failed (attempt %d/%d): %v", attempt, coinGeckoMaxAttempts, lastErr)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("error reading CoinGecko response: %v", err)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("CoinGecko request failed with status %d: %s", resp.StatusCode, string(body))
+			log.Printf("WARNING: CoinGecko request failed (attempt %d/%d): status %d", attempt, coinGeckoMaxAttempts, resp.StatusCode)
+
+			// Honor an explicit wait on top of the normal backoff
+			if wait := parseRetryAfter(resp.Header.Get("Retry-After")); wait > 0 && attempt < coinGeckoMaxAttempts {
+				time.Sleep(wait)
+			}
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("CoinGecko request failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var markets []CoinGeckoMarket
+		if err := json.Unmarshal(body, &markets); err != nil {
+			return nil, fmt.Errorf("error parsing CoinGecko JSON: %v", err)
+		}
+		return markets, nil
+	}
+
+	return nil, lastErr
+}
+
+// lowVolume reports (and logs) a coin whose 24h USD volume is under MIN_24H_VOLUME_USD
+func (bot *TradingBot) lowVolume(symbol string, volume24h float64) bool {
+	if bot.MinVolume24hUSD <= 0 || volume24h >= bot.MinVolume24hUSD {
+		return false
+	}
+
+	logInfo.Printf("SKIP: %s: 24h volume $%.0f below minimum $%.0f\n", strings.TrimSuffix(symbol, "USDT"), volume24h, bot.MinVolume24hUSD)
+	bot.explain(symbol, ReasonLowVolume, "24h volume $%.0f < $%.0f", volume24h, bot.MinVolume24hUSD)
+	return true
+}
+
+// logWatchlistCoin prints a coin added to the watchlist, flagging buy signals and near misses
+func (bot *TradingBot) logWatchlistCoin(coinName string, price, change24h float64) {
+	buySignal := ""
+	switch bot.classifyDropSignal(change24h) {
+	case SignalBuy:
+		buySignal = " " + emoji("\U0001F525 BUY SIGNAL!", "[BUY SIGNAL]")
+	case SignalWatch:
+		buySignal = " " + emoji("\u26A1 WATCH", "[WATCH]") + " (close to threshold)"
+	case SignalRisky, SignalDanger:
+		buySignal = fmt.Sprintf(" %s  DANGER ZONE (>%.0f%% drop)", emoji("\u26A0\uFE0F", "[WARN]"), -bot.BuyDropMax)
+	}
+
+	logDebug.Printf("ADD: %s: $%.4f (%.2f%% 24h)%s\n",
+		coinName, price, change24h, buySignal)
+}
+
+// printWatchlistSummary prints how many coins were loaded and how many are buy or watch candidates
+func (bot *TradingBot) printWatchlistSummary(coins []OptimizedTicker, source string) {
+	logInfo.Printf("\n=== SUMMARY ===\n")
+	logInfo.Printf("Successfully loaded %d tradeable non-stablecoin coins\n", len(coins))
+	logInfo.Printf("Data source: %s\n", source)
+
+	// Show buy opportunities summary
+	buyOpportunities := 0
+	watchList := 0
+	for _, coin := range coins {
+		switch bot.classifyDropSignal(coin.PriceChangePercent) {
+		case SignalBuy:
+			buyOpportunities++
+		case SignalWatch:
+			watchList++
+		}
+	}
+
+	if buyOpportunities > 0 {
+		logInfo.Printf("IMMEDIATE BUY OPPORTUNITIES: %d coins (%s drop range)\n", buyOpportunities, bot.dropBandLabel())
+	}
+	if watchList > 0 {
+		logInfo.Printf("%s %d coins (close to %.1f%% threshold)\n", emoji("\u26A1 WATCH LIST:", "[WATCH LIST]"), watchList, bot.BuyDropMin)
+	}
+	if buyOpportunities == 0 && watchList == 0 {
+		logInfo.Printf("NO IMMEDIATE OPPORTUNITIES: Market is stable\n")
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dryRunPrice returns the current market price used to simulate a fill
+func (bot *TradingBot) dryRunPrice(symbol string) (float64, error) {
+	if price, err := bot.getTickerPrice(symbol); err == nil {
+		return price, nil
+	}
+
+	// Fall back to the last watchlist price if Binance is unreachable
+	if price, ok := bot.currentPrices()[symbol]; ok {
+		return price, nil
+	}
+
+	return 0, fmt.Errorf("no price available for %s", symbol)
+}
+
+// slippedPrice moves a simulated market fill SimulatedSlippage percent against us: buys fill higher, sells lower
+func (bot *TradingBot) slippedPrice(price float64, side OrderSide) float64 {
+	if side == SideBuy {
+		return price * (1 + bot.SimulatedSlippage/100)
+	}
+	return price * (1 - bot.SimulatedSlippage/100)
+}
+
+// newDryRunOrder builds a synthetic order response, registering resting orders for later queries
+func (bot *TradingBot) newDryRunOrder(symbol string, side OrderSide, orderType OrderType, status OrderStatus, price, quantity float64) *OrderResponse {
+	bot.dryRunMu.Lock()
+	defer bot.dryRunMu.Unlock()
+
+	bot.NextDryRunOrderID++
+
+	order := OrderResponse{
+		Symbol:        symbol,
+		OrderID:       bot.NextDryRunOrderID,
+		ClientOrderID: fmt.Sprintf("dryrun-%d", bot.NextDryRunOrderID),
+		TransactTime:  time.Now().UnixNano() / int64(time.Millisecond),
+		Price:         strconv.FormatFloat(price, 'f', -1, 64),
+		OrigQty:       strconv.FormatFloat(quantity, 'f', -1, 64),
+		OrderListID:   -1,
+		ExecutedQty:   "0",
+		QuoteQty:      "0",
+		Status:        status,
+		Type:          orderType,
+		Side:          side,
+	}
+
+	if status == StatusFilled {
+		bot.fillDryRunOrder(&order, price)
+		return &order
+	}
+
+	if bot.DryRunOrders == nil {
+		bot.DryRunOrders = make(map[int64]OrderResponse)
+	}
+	bot.DryRunOrders[order.OrderID] = order
+
+	return &order
+}
+
+// fillDryRunOrder marks a synthetic order as filled at the given price with a simulated commission
+func (bot *TradingBot) fillDryRunOrder(order *OrderResponse, price float64) {
+	quantity, _ := strconv.ParseFloat(order.OrigQty, 64)
+
+	// Buys pay the taker fee in the base asset; sells pay the maker or taker fee in USDT
+	commission := quantity * bot.TakerFeePercent / 100
+	commissionAsset := strings.TrimSuffix(order.Symbol, "USDT")
+	if order.Side == SideSell {
+		feePercent := bot.TakerFeePercent
+		if order.Type == OrderTypeLimit || order.Type == OrderTypeLimitMaker {
+			feePercent = bot.MakerFeePercent
+		}
+		commission = price * quantity * feePercent / 100
+		commissionAsset = "USDT"
+	}
+
+	order.Status = StatusFilled
+	order.ExecutedQty = order.OrigQty
+	order.QuoteQty = strconv.FormatFloat(price*quantity, 'f', -1, 64)
+	order.Fills = []Fill{{
+		Price:           strconv.FormatFloat(price, 'f', -1, 64),
+		Qty:             order.OrigQty,
+		Commission:      strconv.FormatFloat(commission, 'f', -1, 64),
+		CommissionAsset: commissionAsset,
+	}}
+}
+
+// simulateBuyOrder simulates a market buy spending quoteOrderQty at the current price
+func (bot *TradingBot) simulateBuyOrder(symbol string, quoteOrderQty float64) (*OrderResponse, error) {
+	price, err := bot.dryRunPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("dry run buy failed: %v", err)
+	}
+	price = bot.slippedPrice(price, SideBuy)
+
+	logInfo.Printf("   [DRY RUN] Simulated market buy: %.2f USDT of %s at $%.6f\n", quoteOrderQty, symbol, price)
+	return bot.newDryRunOrder(symbol, SideBuy, OrderTypeMarket, StatusFilled, price, quoteOrderQty/price), nil
+}
+
+// simulateLimitBuyOrder fills a limit buy at the current price if the market is at or below the limit,
+// and otherwise reports it as unfilled the way a cancelled order would be
+func (bot *TradingBot) simulateLimitBuyOrder(symbol string, quantity float64, limitPrice float64) (*OrderResponse, error) {
+	price, err := bot.dryRunPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("dry run limit buy failed: %v", err)
+	}
+	if price > limitPrice {
+		return nil, fmt.Errorf("dry run limit buy at $%.8f not filled (market $%.8f), cancelled", limitPrice, price)
+	}
+	// A marketable limit buy takes liquidity too, but never fills above its limit
+	price = min(bot.slippedPrice(price, SideBuy), limitPrice)
+
+	logInfo.Printf("   [DRY RUN] Simulated limit buy: %.6f %s at $%.6f (limit $%.6f)\n", quantity, symbol, price, limitPrice)
+	return bot.newDryRunOrder(symbol, SideBuy, OrderTypeLimit, StatusFilled, price, quantity), nil
+}
+
+// simulateLimitSellOrder records a resting limit sell that fills once the market reaches its price
+func (bot *TradingBot) simulateLimitSellOrder(symbol string, quantity float64, price float64) (*OrderResponse, error) {
+	logInfo.Printf("   [DRY RUN] Simulated limit sell: %.6f %s at $%.6f\n", quantity, symbol, price)
+	return bot.newDryRunOrder(symbol, SideSell, OrderTypeLimit, StatusNew, price, quantity), nil
+}
+
+// simulateOCOSellOrder records a resting OCO sell: a target leg and a stop-limit leg sharing one order list
+func (bot *TradingBot) simulateOCOSellOrder(symbol string, quantity, price, stopPrice, stopLimitPrice float64) (*OCOResponse, error) {
+	logInfo.Printf("   [DRY RUN] Simulated OCO sell: %.6f %s at $%.6f, stop $%.6f (limit $%.6f)\n",
+		quantity, symbol, price, stopPrice, stopLimitPrice)
+
+	stopLeg := bot.newDryRunOrder(symbol, SideSell, OrderTypeStopLossLimit, StatusNew, stopLimitPrice, quantity)
+	targetLeg := bot.newDryRunOrder(symbol, SideSell, OrderTypeLimitMaker, StatusNew, price, quantity)
+
+	listID := stopLeg.OrderID
+	stopLeg.OrderListID, targetLeg.OrderListID = listID, listID
+	stopLeg.StopPrice = strconv.FormatFloat(stopPrice, 'f', -1, 64)
+	bot.dryRunMu.Lock()
+	bot.DryRunOrders[stopLeg.OrderID] = *stopLeg
+	bot.DryRunOrders[targetLeg.OrderID] = *targetLeg
+	bot.dryRunMu.Unlock()
+
+	return &OCOResponse{
+		OrderListID:     listID,
+		ListStatusType:  "EXEC_STARTED",
+		ListOrderStatus: "EXECUTING",
+		OrderReports:    []OrderResponse{*stopLeg, *targetLeg},
+	}, nil
+}
+
+// simulateSellOrder simulates a market sell of quantity at the current price
+func (bot *TradingBot) simulateSellOrder(symbol string, quantity float64) (*OrderResponse, error) {
+	price, err := bot.dryRunPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("dry run sell failed: %v", err)
+	}
+	price = bot.slippedPrice(price, SideSell)
+
+	logInfo.Printf("   [DRY RUN] Simulated market sell: %.6f %s at $%.6f\n", quantity, symbol, price)
+	return bot.newDryRunOrder(symbol, SideSell, OrderTypeMarket, StatusFilled, price, quantity), nil
+}
+
+// simulateQueryOrder returns a synthetic order, filling resting limit sells the market has reached and
+// stop-limit sells the market has fallen through
+func (bot *TradingBot) simulateQueryOrder(symbol string, orderID int64) (*OrderResponse, error) {
+	bot.dryRunMu.Lock()
+	defer bot.dryRunMu.Unlock()
+
+	order, ok := bot.DryRunOrders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("dry run order %d not found", orderID)
+	}
+
+	// The other legs of an OCO may have triggered first, expiring this one
+	if order.OrderListID > 0 {
+		for id, leg := range bot.DryRunOrders {
+			if id != orderID && leg.OrderListID == order.OrderListID && leg.Status == StatusNew {
+				bot.triggerDryRunOrder(symbol, &leg)
+			}
+		}
+		order = bot.DryRunOrders[orderID]
+	}
+
+	if order.Status != StatusNew {
+		// Expired OCO legs are reported once, then forgotten
+		delete(bot.DryRunOrders, orderID)
+		return &order, nil
+	}
+
+	if bot.triggerDryRunOrder(symbol, &order) {
+		delete(bot.DryRunOrders, orderID)
+	}
+	return &order, nil
+}
+
+// triggerDryRunOrder fills a resting simulated order if the current price has reached it, reporting whether it filled.
+// The caller must hold dryRunMu.
+func (bot *TradingBot) triggerDryRunOrder(symbol string, order *OrderResponse) bool {
+	price, err := bot.dryRunPrice(symbol)
+	if err != nil {
+		return false
+	}
+
+	limitPrice, _ := strconv.ParseFloat(order.Price, 64)
+	triggered := price >= limitPrice
+	if order.Type == OrderTypeStopLossLimit {
+		stopPrice, _ := strconv.ParseFloat(order.StopPrice, 64)
+		triggered = price <= stopPrice
+	}
+	if !triggered {
+		return false
+	}
+
+	// Keep the filled order until it is queried so its fill can still be reported
+	bot.fillDryRunOrder(order, limitPrice)
+	bot.DryRunOrders[order.OrderID] = *order
+	bot.expireDryRunOrderList(order.OrderListID, order.OrderID)
+	return true
+}
+
+// expireDryRunOrderList expires the other legs of a simulated OCO once one leg fills
+func (bot *TradingBot) expireDryRunOrderList(orderListID, exceptOrderID int64) {
+	if orderListID <= 0 {
+		return
+	}
+
+	for id, order := range bot.DryRunOrders {
+		if order.OrderListID == orderListID && id != exceptOrderID {
+			order.Status = StatusExpired
+			bot.DryRunOrders[id] = order
+		}
+	}
+}
+
+// simulateCancelOrder cancels a synthetic resting order
+func (bot *TradingBot) simulateCancelOrder(orderID int64) (*OrderResponse, error) {
+	bot.dryRunMu.Lock()
+	defer bot.dryRunMu.Unlock()
+
+	order, ok := bot.DryRunOrders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("dry run order %d not found", orderID)
+	}
+
+	// Cancelling either OCO leg cancels the whole list, as on Binance
+	delete(bot.DryRunOrders, orderID)
+	if order.OrderListID > 0 {
+		for id, leg := range bot.DryRunOrders {
+			if leg.OrderListID == order.OrderListID {
+				delete(bot.DryRunOrders, id)
+			}
+		}
+	}
+	order.Status = StatusCanceled
+	return &order, nil
+}
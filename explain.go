@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DecisionReason is a reason code explaining why a coin was or wasn't traded
+type DecisionReason string
+
+const (
+	ReasonOutsideWatchlist  DecisionReason = "outside-watchlist"  // Beyond the top-N coins considered
+	ReasonStablecoin        DecisionReason = "stablecoin"         // Pegged asset, excluded from trading
+	ReasonNotOnBinance      DecisionReason = "not-on-binance"     // No trading USDT pair on Binance
+	ReasonLowVolume         DecisionReason = "low-volume"         // 24h volume under MIN_24H_VOLUME_USD
+	ReasonBlacklisted       DecisionReason = "blacklisted"        // Listed in SYMBOL_BLACKLIST
+	ReasonNotWhitelisted    DecisionReason = "not-whitelisted"    // SYMBOL_WHITELIST is set and doesn't include it
+	ReasonSafetyLimit       DecisionReason = "safety-limit"       // Drop exceeds the safety cutoff
+	ReasonDropTooSmall      DecisionReason = "drop-too-small"     // Not down enough to trigger a buy
+	ReasonDropTooDeep       DecisionReason = "drop-too-deep"      // Past the buy band but inside the safety limit
+	ReasonAbnormalVolume    DecisionReason = "abnormal-volume"    // Volume spike alongside the drop
+	ReasonRebuyCooldown     DecisionReason = "rebuy-cooldown"     // Sold too recently (REBUY_COOLDOWN_MINUTES)
+	ReasonInsufficientFunds DecisionReason = "insufficient-funds" // Not enough budget for the trade
+	ReasonPositionLimit     DecisionReason = "position-limit"     // MAX_OPEN_POSITIONS reached
+	ReasonExposureLimit     DecisionReason = "exposure-limit"     // MAX_TOTAL_EXPOSURE_USDT would be exceeded
+	ReasonDailyLossLimit    DecisionReason = "daily-loss-limit"   // MAX_DAILY_LOSS_USDT reached, buying halted until tomorrow
+	ReasonBelowMinNotional  DecisionReason = "below-min-notional" // Investment amount under the symbol's minimum order
+	ReasonOrderFailed       DecisionReason = "order-failed"       // Binance rejected or failed the buy
+	ReasonBought            DecisionReason = "bought"             // Buy order executed
+	ReasonWouldBuy          DecisionReason = "would-buy"          // Preview mode: a buy would be placed
+)
+
+// explain logs the decision for a coin when explain mode is enabled
+func (bot *TradingBot) explain(symbol string, reason DecisionReason, format string, args ...interface{}) {
+	if !bot.ExplainMode {
+		return
+	}
+
+	logInfo.Printf("   [EXPLAIN] %-10s %-18s %s\n", strings.TrimSuffix(symbol, "USDT"), reason, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Size-based rotation defaults for LOG_FILE
+const (
+	defaultLogMaxSizeMB  = 10
+	defaultLogMaxBackups = 3
+)
+
+// eventLog records structured trading events; it discards everything unless LOG_FILE is set
+var eventLog = slog.New(slog.DiscardHandler)
+
+// LogLevel is the minimum severity of console output, set with LOG_LEVEL
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// consoleLevel hides console output below this level; per-coin HOLD/WATCH lines are debug
+var consoleLevel = LogLevelInfo
+
+// parseLogLevel maps a LOG_LEVEL value (debug, info, warn, error) to its level
+func parseLogLevel(value string) (LogLevel, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return LogLevelDebug, true
+	case "info":
+		return LogLevelInfo, true
+	case "warn", "warning":
+		return LogLevelWarn, true
+	case "error":
+		return LogLevelError, true
+	}
+	return LogLevelInfo, false
+}
+
+// noEmoji swaps console emoji for plain ASCII tags, set with NO_EMOJI for log-file-friendly output
+var noEmoji bool
+
+// emoji returns symbol, or tag when NO_EMOJI is set
+func emoji(symbol, tag string) string {
+	if noEmoji {
+		return tag
+	}
+	return symbol
+}
+
+// leveledPrinter prints bot output to stdout only when its level is enabled by LOG_LEVEL
+type leveledPrinter LogLevel
+
+var (
+	logDebug = leveledPrinter(LogLevelDebug)
+	logInfo  = leveledPrinter(LogLevelInfo)
+	logWarn  = leveledPrinter(LogLevelWarn)
+	logError = leveledPrinter(LogLevelError)
+)
+
+func (p leveledPrinter) enabled() bool {
+	return LogLevel(p) >= consoleLevel
+}
+
+func (p leveledPrinter) Printf(format string, args ...any) {
+	if p.enabled() {
+		fmt.Printf(format, args...)
+	}
+}
+
+func (p leveledPrinter) Println(args ...any) {
+	if p.enabled() {
+		fmt.Println(args...)
+	}
+}
+
+func (p leveledPrinter) Print(args ...any) {
+	if p.enabled() {
+		fmt.Print(args...)
+	}
+}
+
+// messageLevel classifies a standard logger line by its WARNING/ERROR prefix
+func messageLevel(message string) LogLevel {
+	switch {
+	case strings.HasPrefix(message, "ERROR"):
+		return LogLevelError
+	case strings.HasPrefix(message, "WARNING"):
+		return LogLevelWarn
+	}
+	return LogLevelInfo
+}
+
+// consoleLogWriter drops standard logger lines below LOG_LEVEL before they reach the console
+type consoleLogWriter struct {
+	w io.Writer
+}
+
+func (c consoleLogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSpace(string(p))
+	if fields := strings.SplitN(message, " ", 3); len(fields) == 3 {
+		message = fields[2]
+	}
+	if messageLevel(message) < consoleLevel {
+		return len(p), nil
+	}
+	return c.w.Write(p)
+}
+
+// setupLogging applies LOG_LEVEL and NO_EMOJI to console output and writes structured JSON logs to LOG_FILE when set.
+// Warnings from the standard logger are mirrored into the file as well.
+func setupLogging() {
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		level, ok := parseLogLevel(value)
+		if !ok {
+			log.Printf("WARNING: Invalid LOG_LEVEL %q (use debug, info, warn or error), using info", value)
+		}
+		consoleLevel = level
+	}
+	log.SetOutput(consoleLogWriter{os.Stderr})
+	noEmoji = getEnvBool("NO_EMOJI", false)
+
+	path := strings.TrimSpace(os.Getenv("LOG_FILE"))
+	if path == "" {
+		return
+	}
+
+	maxSizeMB := getEnvInt("LOG_MAX_SIZE_MB", defaultLogMaxSizeMB)
+	if maxSizeMB <= 0 {
+		log.Printf("WARNING: LOG_MAX_SIZE_MB must be positive, using default %d", defaultLogMaxSizeMB)
+		maxSizeMB = defaultLogMaxSizeMB
+	}
+	maxBackups := getEnvInt("LOG_MAX_BACKUPS", defaultLogMaxBackups)
+	if maxBackups < 0 {
+		log.Printf("WARNING: LOG_MAX_BACKUPS cannot be negative, using default %d", defaultLogMaxBackups)
+		maxBackups = defaultLogMaxBackups
+	}
+
+	file, err := newRotatingFile(path, int64(maxSizeMB)*1024*1024, maxBackups)
+	if err != nil {
+		log.Printf("WARNING: Could not open LOG_FILE %s, file logging disabled: %v", path, err)
+		return
+	}
+
+	eventLog = slog.New(slog.NewJSONHandler(file, nil))
+	log.SetOutput(io.MultiWriter(consoleLogWriter{os.Stderr}, logBridge{}))
+	fmt.Printf("Writing JSON logs to %s (rotating at %d MB, keeping %d backups)\n", path, maxSizeMB, maxBackups)
+}
+
+// logEvent writes a structured event such as "buy", "sell" or "cycle_error" to the JSON log
+func logEvent(level slog.Level, event string, attrs ...any) {
+	eventLog.Log(context.Background(), level, event, append([]any{"event", event}, attrs...)...)
+}
+
+// logBridge forwards standard logger lines into the JSON log, using the WARNING/ERROR prefix as the level
+type logBridge struct{}
+
+func (logBridge) Write(p []byte) (int, error) {
+	message := strings.TrimSpace(string(p))
+
+	// Strip the standard logger's date/time prefix so only the message is recorded
+	if fields := strings.SplitN(message, " ", 3); len(fields) == 3 {
+		message = fields[2]
+	}
+
+	level := slog.LevelInfo
+	switch messageLevel(message) {
+	case LogLevelError:
+		level = slog.LevelError
+	case LogLevelWarn:
+		level = slog.LevelWarn
+	}
+
+	logEvent(level, "log", "message", message)
+	return len(p), nil
+}
+
+// rotatingFile is an append-only log file that rolls over to path.1, path.2, ... once it exceeds maxSize
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newRotatingFile opens (or creates) the log file, appending to any existing content
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size+int64(len(p)) > r.maxSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			// Keep writing to the current file rather than losing log lines
+			fmt.Fprintf(os.Stderr, "WARNING: Log rotation failed: %v\n", err)
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts path.N-1 -> path.N down to path -> path.1, dropping the oldest backup
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	if r.maxBackups == 0 {
+		os.Remove(r.path)
+	} else {
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			r.open()
+			return err
+		}
+	}
+
+	return r.open()
+}
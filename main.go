@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"strings"
 )
@@ -11,13 +12,30 @@ func showHelp() {
 	fmt.Println("==================================================")
 	fmt.Println()
 	fmt.Println("STRATEGY:")
-	fmt.Println("  • Data: CoinMarketCap API (Top 20 coins, excluding stablecoins)")
-	fmt.Println("  • Buy: When coins drop 5-10% in 24h")
-	fmt.Println("  • Sell: When coins reach +5% profit")
-	fmt.Println("  • Trading: Binance API (execution only)")
+	bullet := emoji("\u2022", "-")
+	fmt.Println(" ", bullet, "Data: CoinMarketCap API (Top 20 coins, excluding stablecoins)")
+	fmt.Println(" ", bullet, "Buy: When coins drop 5-10% in 24h")
+	fmt.Println(" ", bullet, "Sell: When coins reach +5% profit (PROFIT_TARGET_PERCENT)")
+	fmt.Println(" ", bullet, "Trading: Binance API (execution only)")
 	fmt.Println()
 	fmt.Println("Available Commands:")
 	fmt.Println("  start             Start the automated trading bot (REAL MONEY)")
+	fmt.Println("    --verbose       Explain why each coin was or wasn't traded (same as EXPLAIN=true)")
+	fmt.Println("    --once          Run a single cycle, save state and exit, e.g. from cron (same as RUN_ONCE=true)")
+	fmt.Println("    --yes           Skip the CONFIRM prompt before live trading (same as AUTO_CONFIRM=true)")
+	fmt.Println("  status            Show open positions and budget from the saved state file")
+	fmt.Println("  history           List completed trades from the saved state file with a P/L summary")
+	fmt.Println("    --since <date>  Only trades sold on or after this date (YYYY-MM-DD)")
+	fmt.Println("  positions         List all open orders on the Binance account (ignores local state)")
+	fmt.Println("  balance           Show the free USDT balance and all non-zero asset balances")
+	fmt.Println("  cancel <orderId> <symbol>  Cancel an open order, e.g. a stuck limit sell")
+	fmt.Println("  sell <symbol>     Market-sell the whole free balance of a coin (emergency exit)")
+	fmt.Println("  dust              List leftover balances too small to sell")
+	fmt.Println("    --convert       Convert those balances to BNB through Binance's dust endpoint")
+	fmt.Println("  preview           Show what the bot would buy right now, then exit (no orders)")
+	fmt.Println("  test-binance      Check Binance connectivity, clock skew, API key access and trading permission")
+	fmt.Println("  test-cmc          Fetch the CoinMarketCap watchlist once and show each coin's signal")
+	fmt.Println("  backtest <symbol> <start> <end>  Replay the strategy on daily Binance candles (dates YYYY-MM-DD)")
 	fmt.Println("  help              Show this help message")
 	fmt.Println()
 	fmt.Println("Usage: ./trading-bot <command>")
@@ -43,6 +61,22 @@ func main() {
 		}
 	}
 
+	// Settings from the optional config file fill in anything .env and the environment leave unset
+	configPath := strings.TrimSpace(os.Getenv("CONFIG_FILE"))
+	if configPath == "" {
+		configPath = defaultConfigFile
+	}
+	if err := loadConfigFile(configPath); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+
+	// Credentials from secret files replace any set directly
+	if err := loadSecretFiles(); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+
+	setupLogging()
+
 	if len(os.Args) < 2 {
 		showHelp()
 		return
@@ -54,13 +88,46 @@ func main() {
 	case "help", "-h", "--help":
 		showHelp()
 	case "start":
+		for _, arg := range os.Args[2:] {
+			switch arg {
+			case "--verbose", "--explain":
+				os.Setenv("EXPLAIN", "true")
+			case "--once":
+				os.Setenv("RUN_ONCE", "true")
+			case "--yes", "-y":
+				os.Setenv("AUTO_CONFIRM", "true")
+			}
+		}
+
 		fmt.Println("Starting Optimized Trading Bot...")
 		fmt.Println("Strategy: CoinMarketCap Top 20 (no stablecoins) + Binance execution")
-		fmt.Println("Target: 5-10% drops with 5% profit targets")
+		fmt.Println("Target: 5-10% drops with configurable profit targets (default 5%)")
 		fmt.Println("Now starting the optimized trading bot...")
 		StartTradingBot()
+	case "status":
+		ShowStatus()
+	case "history":
+		ShowHistory(os.Args[2:])
+	case "positions":
+		ShowOpenOrders()
+	case "balance":
+		ShowBalance()
+	case "cancel":
+		CancelOrder(os.Args[2:])
+	case "sell":
+		SellPosition(os.Args[2:])
+	case "dust":
+		CleanupDust(os.Args[2:])
+	case "preview", "plan":
+		PreviewTradingBot()
+	case "test-binance":
+		TestBinance()
+	case "test-cmc":
+		TestCMC()
+	case "backtest":
+		RunBacktest(os.Args[2:])
 	default:
-		fmt.Printf("❌ Unknown command: %s\n", command)
+		fmt.Printf("%s Unknown command: %s\n", emoji("\u274C", "[ERROR]"), command)
 		fmt.Println("Run './trading-bot help' for available commands")
 	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// defaultMaintenanceCooldownMinutes is how long trading pauses after Binance answers 503
+const defaultMaintenanceCooldownMinutes = 15
+
+// ErrTradingPaused is returned by a trading cycle skipped for a Binance outage, so health checks don't
+// report the bot as healthy while it can't trade
+var ErrTradingPaused = errors.New("trading paused while Binance is unavailable")
+
+// watchMaintenance pauses trading for MaintenanceCooldown when an order request failed because Binance is
+// unavailable, alerting once per outage. It returns err unchanged so callers can wrap their Binance calls.
+func (bot *TradingBot) watchMaintenance(err error) error {
+	if err == nil || bot.MaintenanceCooldown <= 0 || !isBinanceUnavailable(err) {
+		return err
+	}
+
+	bot.maintenanceMu.Lock()
+	starting := bot.maintenanceSince.IsZero()
+	if starting {
+		bot.maintenanceSince = time.Now()
+	}
+	bot.maintenanceUntil = time.Now().Add(bot.MaintenanceCooldown)
+	bot.maintenanceMu.Unlock()
+
+	if starting {
+		logWarn.Printf("WARNING: Binance is unavailable, likely for maintenance (%v) - pausing trading for %s\n",
+			err, bot.MaintenanceCooldown)
+		bot.notify(fmt.Sprintf("Binance is unavailable, likely for maintenance - trading paused for %s", bot.MaintenanceCooldown))
+		logEvent(slog.LevelWarn, "binance_unavailable", "error", err.Error(), "cooldown", bot.MaintenanceCooldown.String())
+	}
+	return err
+}
+
+// inMaintenance reports whether trading is paused for a Binance outage
+func (bot *TradingBot) inMaintenance() bool {
+	bot.maintenanceMu.Lock()
+	defer bot.maintenanceMu.Unlock()
+
+	return !bot.maintenanceSince.IsZero()
+}
+
+// maintenanceOver reports whether a cycle may trade. Once the cooldown has passed it pings Binance,
+// resuming trading if it answers and extending the pause if it doesn't.
+func (bot *TradingBot) maintenanceOver() bool {
+	bot.maintenanceMu.Lock()
+	since, until := bot.maintenanceSince, bot.maintenanceUntil
+	bot.maintenanceMu.Unlock()
+
+	if since.IsZero() {
+		return true
+	}
+	if time.Now().Before(until) {
+		logInfo.Printf("Binance unavailable since %s - trading paused until %s\n",
+			since.Format("15:04:05"), until.Format("15:04:05"))
+		return false
+	}
+
+	if err := pingBinance(bot.httpClient, bot.Binance.Config.BaseURL); err != nil {
+		bot.maintenanceMu.Lock()
+		bot.maintenanceUntil = time.Now().Add(bot.MaintenanceCooldown)
+		bot.maintenanceMu.Unlock()
+		logWarn.Printf("WARNING: Binance still unavailable (%v) - trading paused for another %s\n", err, bot.MaintenanceCooldown)
+		return false
+	}
+
+	bot.maintenanceMu.Lock()
+	bot.maintenanceSince, bot.maintenanceUntil = time.Time{}, time.Time{}
+	bot.maintenanceMu.Unlock()
+
+	outage := time.Since(since).Round(time.Second)
+	logInfo.Printf("Binance connectivity restored after %s - resuming trading\n", outage)
+	bot.notify(fmt.Sprintf("Binance is back after %s - trading resumed", outage))
+	logEvent(slog.LevelInfo, "binance_restored", "outage", outage.String())
+	return true
+}
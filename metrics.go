@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// handleMetrics exports the last cycle's snapshot in the Prometheus text exposition format
+func (bot *TradingBot) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bot.mu.RLock()
+	status := bot.status
+	bot.mu.RUnlock()
+
+	dryRun := 0.0
+	if status.DryRun {
+		dryRun = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "rebound_trades_total", "counter", "Completed trades (buy and sell)", float64(status.Stats.TotalTrades))
+	writeMetric(w, "rebound_winning_trades_total", "counter", "Completed trades closed at a profit", float64(status.Stats.WinningTrades))
+	writeMetric(w, "rebound_losing_trades_total", "counter", "Completed trades closed at a loss", float64(status.Stats.LosingTrades))
+	writeMetric(w, "rebound_net_profit_usdt", "gauge", "Realized net profit across completed trades", status.Stats.NetProfit)
+	writeMetric(w, "rebound_fees_usdt_total", "counter", "Commission paid across completed trades", status.Stats.TotalFees)
+	writeMetric(w, "rebound_open_positions", "gauge", "Positions currently held", float64(len(status.OpenPositions)))
+	writeMetric(w, "rebound_available_budget_usdt", "gauge", "Budget not tied up in open positions", status.AvailableBudget)
+	writeMetric(w, "rebound_total_budget_usdt", "gauge", "Budget the bot started with", status.TotalBudget)
+	writeMetric(w, "rebound_cycle_duration_seconds", "gauge", "Duration of the most recent trading cycle", status.LastCycleSecs)
+	writeMetric(w, "rebound_cycles_total", "counter", "Trading cycles run since startup", float64(status.CyclesRun))
+	writeMetric(w, "rebound_cycle_errors_total", "counter", "Trading cycles that failed since startup", float64(status.CycleErrors))
+	writeMetric(w, "rebound_binance_used_weight", "gauge", "Binance request weight used in the current minute at the last check", float64(status.UsedWeight))
+	writeMetric(w, "rebound_dry_run", "gauge", "1 when orders are simulated", dryRun)
+}
+
+// writeMetric writes one unlabelled metric with its HELP and TYPE lines
+func writeMetric(w io.Writer, name, metricType, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, metricType, name, value)
+}
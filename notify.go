@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// discordMaxRetryWait caps how long a rate-limited Discord alert waits before its single retry
+const discordMaxRetryWait = 10 * time.Second
+
+// notify sends a message to every configured alert channel
+func (bot *TradingBot) notify(message string) {
+	bot.notifyTelegram(message)
+	bot.notifyDiscord(message)
+}
+
+// notifyTelegram sends a message to the configured Telegram chat, doing nothing when Telegram is not configured.
+// Failures are logged rather than returned so alerts never interrupt trading.
+func (bot *TradingBot) notifyTelegram(message string) {
+	if bot.TelegramBotToken == "" || bot.TelegramChatID == "" {
+		return
+	}
+
+	if bot.DryRun {
+		message = "[DRY RUN] " + message
+	}
+
+	params := url.Values{}
+	params.Set("chat_id", bot.TelegramChatID)
+	params.Set("text", message)
+
+	apiURL := "https://api.telegram.org/bot" + bot.TelegramBotToken + "/sendMessage"
+	resp, err := bot.httpClient.Post(apiURL, "application/x-www-form-urlencoded", strings.NewReader(params.Encode()))
+	if err != nil {
+		// The request error includes the URL, which contains the bot token
+		log.Printf("WARNING: Telegram notification failed: %v", strings.ReplaceAll(err.Error(), bot.TelegramBotToken, "***"))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		log.Printf("WARNING: Telegram notification failed with status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// notifyDiscord posts a message to the configured Discord webhook, doing nothing when no webhook is set.
+// A 429 response is retried once after the wait Discord asks for.
+func (bot *TradingBot) notifyDiscord(message string) {
+	if bot.DiscordWebhookURL == "" {
+		return
+	}
+
+	if bot.DryRun {
+		message = "[DRY RUN] " + message
+	}
+
+	payload, err := json.Marshal(map[string]string{"content": message})
+	if err != nil {
+		log.Printf("WARNING: Discord notification failed: %v", err)
+		return
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		resp, err := bot.httpClient.Post(bot.DiscordWebhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			// The request error includes the webhook URL, which is a secret
+			log.Printf("WARNING: Discord notification failed: %v", strings.ReplaceAll(err.Error(), bot.DiscordWebhookURL, "***"))
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt == 1 {
+			wait := discordRetryAfter(resp.Header.Get("Retry-After"), body)
+			log.Printf("WARNING: Discord rate limited, retrying in %s", wait)
+			time.Sleep(wait)
+			continue
+		}
+
+		// Webhooks answer 204 No Content on success
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			log.Printf("WARNING: Discord notification failed with status %d: %s", resp.StatusCode, string(body))
+		}
+		return
+	}
+}
+
+// discordRetryAfter returns how long Discord asked us to wait, from the JSON retry_after (seconds) or the header
+func discordRetryAfter(header string, body []byte) time.Duration {
+	var rateLimit struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+
+	wait := time.Second
+	if err := json.Unmarshal(body, &rateLimit); err == nil && rateLimit.RetryAfter > 0 {
+		wait = time.Duration(rateLimit.RetryAfter * float64(time.Second))
+	} else if seconds, err := strconv.ParseFloat(header, 64); err == nil && seconds > 0 {
+		wait = time.Duration(seconds * float64(time.Second))
+	}
+
+	if wait > discordMaxRetryWait {
+		return discordMaxRetryWait
+	}
+	return wait
+}
+
+// buyMessage describes a filled buy for notifications
+func buyMessage(pos TradingPosition) string {
+	return fmt.Sprintf("BUY %s: %.6f at $%.6f (%.2f USDT, %.2f%% drop) - target $%.6f",
+		strings.TrimSuffix(pos.Symbol, "USDT"), pos.Quantity, pos.BuyPrice, pos.InvestedAmount,
+		pos.DropPercentage, pos.TargetSellPrice)
+}
+
+// sellMessage describes a closed position for notifications
+func sellMessage(trade CompletedTrade) string {
+	return fmt.Sprintf("SELL %s: %.6f at $%.6f (bought $%.6f) - P/L %+.2f USDT (%+.2f%%)",
+		strings.TrimSuffix(trade.Symbol, "USDT"), trade.Quantity, trade.SellPrice, trade.BuyPrice,
+		trade.Profit, trade.ProfitPercent)
+}
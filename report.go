@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// performanceReport summarizes completed trades and open positions for the periodic report
+func (bot *TradingBot) performanceReport() string {
+	bot.stateMu.RLock()
+	stats := bot.Stats
+	trades := append([]CompletedTrade(nil), bot.CompletedTrades...)
+	positions := append([]TradingPosition(nil), bot.Positions...)
+	availableBudget := bot.AvailableBudget
+	bot.stateMu.RUnlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "PERFORMANCE REPORT (running %s)\n", time.Since(bot.StartTime).Round(time.Minute))
+
+	if stats.TotalTrades == 0 {
+		b.WriteString("No completed trades yet\n")
+	} else {
+		fmt.Fprintf(&b, "Trades: %d (%d won / %d lost) | Win rate: %.1f%%\n",
+			stats.TotalTrades, stats.WinningTrades, stats.LosingTrades, stats.WinRate)
+		fmt.Fprintf(&b, "Net profit: %+.2f USDT (fees %.2f USDT)\n", stats.NetProfit, stats.TotalFees)
+
+		// Stats and trades are saved separately, so an old or edited state file can have stats without trades
+		if len(trades) > 0 {
+			best, worst := trades[0], trades[0]
+			for _, trade := range trades[1:] {
+				if trade.Profit > best.Profit {
+					best = trade
+				}
+				if trade.Profit < worst.Profit {
+					worst = trade
+				}
+			}
+			fmt.Fprintf(&b, "Best trade: %s %+.2f USDT (%+.2f%%)\n",
+				strings.TrimSuffix(best.Symbol, "USDT"), best.Profit, best.ProfitPercent)
+			fmt.Fprintf(&b, "Worst trade: %s %+.2f USDT (%+.2f%%)\n",
+				strings.TrimSuffix(worst.Symbol, "USDT"), worst.Profit, worst.ProfitPercent)
+		}
+	}
+
+	invested, value := 0.0, 0.0
+	for _, pos := range positions {
+		invested += pos.InvestedAmount
+		value += pos.CurrentValue
+	}
+	fmt.Fprintf(&b, "Open positions: %d | Exposure: %.2f USDT | Value: %.2f USDT\n", len(positions), invested, value)
+	fmt.Fprintf(&b, "Realized P/L: %+.2f USDT | Unrealized P/L: %+.2f USDT\n", bot.realizedPnL(), bot.unrealizedPnL())
+	fmt.Fprintf(&b, "Available budget: %.2f USDT", availableBudget)
+
+	return b.String()
+}
+
+// sendReport prints the performance report and sends it to the configured alert channels
+func (bot *TradingBot) sendReport() {
+	report := bot.performanceReport()
+
+	logInfo.Printf("\n%s\n", report)
+	bot.notify(report)
+
+	stats := bot.getStatsSnapshot()
+	logEvent(slog.LevelInfo, "report", "trades", stats.TotalTrades, "winRate", stats.WinRate,
+		"netProfit", stats.NetProfit, "openPositions", len(bot.getPositionsSnapshot()))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// BotStatus is the JSON document served by /status
+type BotStatus struct {
+	DryRun          bool              `json:"dryRun"`
+	StartTime       time.Time         `json:"startTime"`
+	Uptime          string            `json:"uptime"`
+	TotalBudget     float64           `json:"totalBudget"`
+	AvailableBudget float64           `json:"availableBudget"`
+	OpenPositions   []TradingPosition `json:"openPositions"`
+	Stats           PaperTradingStats `json:"stats"`
+	RealizedPnL     float64           `json:"realizedPnl"`
+	UnrealizedPnL   float64           `json:"unrealizedPnl"`
+	LastCycleTime   time.Time         `json:"lastCycleTime"`
+	LastCycleError  string            `json:"lastCycleError,omitempty"`
+	LastCycleSecs   float64           `json:"lastCycleSeconds"`
+	CyclesRun       int               `json:"cyclesRun"`
+	CycleErrors     int               `json:"cycleErrors"`
+	UsedWeight      int               `json:"usedWeight"`
+	WeightLimit     int               `json:"weightLimit"`
+}
+
+// startHTTPServer starts the optional HTTP server in the background
+func (bot *TradingBot) startHTTPServer(port string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", bot.handleHealthz)
+	mux.HandleFunc("/health", bot.handleHealth)
+	mux.HandleFunc("/status", bot.handleStatus)
+	mux.HandleFunc("/metrics", bot.handleMetrics)
+
+	// Serve the startup state until the first cycle refreshes it
+	status := bot.snapshotStatus()
+	bot.mu.Lock()
+	bot.status = status
+	bot.mu.Unlock()
+
+	addr := ":" + port
+	logInfo.Printf("HTTP server listening on %s (GET /health, /healthz, /status, /metrics)\n", addr)
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("HTTP server stopped: %v", err)
+		}
+	}()
+}
+
+// handleHealthz reports 200 only if the last trading cycle succeeded within the staleness window
+func (bot *TradingBot) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bot.mu.RLock()
+	lastCycle := bot.LastCycleTime
+	lastErr := bot.LastCycleError
+	staleAfter := bot.HealthStaleAfter
+	bot.mu.RUnlock()
+
+	switch {
+	case lastCycle.IsZero():
+		http.Error(w, "no successful trading cycle yet", http.StatusServiceUnavailable)
+	case lastErr != "":
+		http.Error(w, "last trading cycle failed: "+lastErr, http.StatusServiceUnavailable)
+	case time.Since(lastCycle) > staleAfter:
+		http.Error(w, fmt.Sprintf("last successful cycle %s ago (limit %s)",
+			time.Since(lastCycle).Round(time.Second), staleAfter), http.StatusServiceUnavailable)
+	default:
+		fmt.Fprintf(w, "ok - last successful cycle at %s\n", lastCycle.Format(time.RFC3339))
+	}
+}
+
+// handleHealth is a plain liveness check: 200 with the process uptime while the bot is running
+func (bot *TradingBot) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fmt.Fprintf(w, "ok - uptime %s\n", time.Since(bot.StartTime).Round(time.Second))
+}
+
+// handleStatus returns open positions, budget and stats, along with the outcome of the last cycle
+func (bot *TradingBot) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bot.mu.RLock()
+	status := bot.status
+	bot.mu.RUnlock()
+	status.Uptime = time.Since(status.StartTime).Round(time.Second).String()
+
+	// Positions, budget and stats are read live so buys and sells show up mid-cycle
+	status.OpenPositions = bot.getPositionsSnapshot()
+	status.AvailableBudget = bot.getAvailableBudget()
+	status.Stats = bot.getStatsSnapshot()
+	status.RealizedPnL = bot.realizedPnL()
+	status.UnrealizedPnL = bot.unrealizedPnL()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("WARNING: Failed to write /status response: %v", err)
+	}
+}
+
+// snapshotStatus copies the state served by /status and /metrics. Cycle fields such as LastCycleDuration
+// are only written by the trading loop, so it must be called from that goroutine.
+func (bot *TradingBot) snapshotStatus() BotStatus {
+	return BotStatus{
+		DryRun:          bot.DryRun,
+		StartTime:       bot.StartTime,
+		TotalBudget:     bot.TotalBudget,
+		AvailableBudget: bot.getAvailableBudget(),
+		OpenPositions:   bot.getPositionsSnapshot(),
+		Stats:           bot.getStatsSnapshot(),
+		RealizedPnL:     bot.realizedPnL(),
+		UnrealizedPnL:   bot.unrealizedPnL(),
+		LastCycleSecs:   bot.LastCycleDuration.Seconds(),
+		UsedWeight:      bot.UsedWeight,
+		WeightLimit:     bot.WeightLimit,
+	}
+}
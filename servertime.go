@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How long after its timestamp Binance accepts a signed request (RECV_WINDOW_MS)
+const (
+	defaultRecvWindowMs = 5000
+	maxRecvWindowMs     = 60000 // Binance rejects anything larger
+)
+
+var (
+	recvWindowOnce  sync.Once
+	recvWindowValue int
+)
+
+// serverTimeOffsetMs is Binance server time minus local time, applied to every signed request timestamp
+var serverTimeOffsetMs atomic.Int64
+
+// syncServerTime measures the offset between the local clock and Binance server time
+func syncServerTime(client *http.Client, baseURL string) error {
+	requestTime := time.Now()
+	resp, err := client.Get(baseURL + "/api/v3/time")
+	if err != nil {
+		return fmt.Errorf("error getting server time: %v", err)
+	}
+	defer resp.Body.Close()
+	responseTime := time.Now()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading server time response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server time request failed: %w", parseBinanceError(resp.StatusCode, body))
+	}
+
+	var serverTime struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.Unmarshal(body, &serverTime); err != nil {
+		return fmt.Errorf("error parsing server time: %v", err)
+	}
+
+	// Assume the server stamped the response halfway through the round trip
+	localMs := requestTime.Add(responseTime.Sub(requestTime)/2).UnixNano() / int64(time.Millisecond)
+	offset := serverTime.ServerTime - localMs
+	serverTimeOffsetMs.Store(offset)
+
+	if offset > 1000 || offset < -1000 {
+		fmt.Printf("WARNING: Local clock is %dms off Binance server time - adjusting request timestamps\n", offset)
+	}
+
+	return nil
+}
+
+// pingBinance checks that the Binance REST API is reachable
+func pingBinance(client *http.Client, baseURL string) error {
+	resp, err := client.Get(baseURL + "/api/v3/ping")
+	if err != nil {
+		return fmt.Errorf("error pinging Binance: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ping failed: %w", parseBinanceError(resp.StatusCode, body))
+	}
+	return nil
+}
+
+// binanceTimestamp returns the current time in milliseconds, corrected to Binance server time
+func binanceTimestamp() int64 {
+	return time.Now().UnixNano()/int64(time.Millisecond) + serverTimeOffsetMs.Load()
+}
+
+// recvWindowMs returns the configured recvWindow for signed requests, read once from RECV_WINDOW_MS
+func recvWindowMs() int {
+	recvWindowOnce.Do(func() {
+		recvWindowValue = getEnvInt("RECV_WINDOW_MS", defaultRecvWindowMs)
+		if recvWindowValue <= 0 || recvWindowValue > maxRecvWindowMs {
+			log.Printf("WARNING: RECV_WINDOW_MS must be between 1 and %d, using default %d", maxRecvWindowMs, defaultRecvWindowMs)
+			recvWindowValue = defaultRecvWindowMs
+		}
+	})
+	return recvWindowValue
+}
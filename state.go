@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Where bot state is persisted when STATE_FILE is unset
+const (
+	defaultStateFile       = "bot_state.json"
+	defaultDryRunStateFile = "bot_state.dryrun.json" // Keeps paper trades apart from live positions
+)
+
+// BotState is the persisted portion of the bot (no credentials or runtime config)
+type BotState struct {
+	SavedAt         time.Time         `json:"savedAt"`
+	StartTime       time.Time         `json:"startTime"`
+	TotalBudget     float64           `json:"totalBudget"`
+	AvailableBudget float64           `json:"availableBudget"`
+	NextPositionID  int               `json:"nextPositionId"`
+	Positions       []TradingPosition `json:"positions"`
+	CompletedTrades []CompletedTrade  `json:"completedTrades"`
+	Stats           PaperTradingStats `json:"stats"`
+
+	// Dry run only: simulated resting orders, so they survive restarts
+	DryRunOrders      map[int64]OrderResponse `json:"dryRunOrders,omitempty"`
+	NextDryRunOrderID int64                   `json:"nextDryRunOrderId,omitempty"`
+}
+
+// stateFilePath returns the configured state file path; dry runs default to a separate file
+func stateFilePath() string {
+	if path := os.Getenv("STATE_FILE"); path != "" {
+		return path
+	}
+	if getEnvBool("DRY_RUN", false) {
+		return defaultDryRunStateFile
+	}
+	return defaultStateFile
+}
+
+// SaveState writes the bot state to disk as JSON, replacing the file atomically
+func (bot *TradingBot) SaveState(path string) error {
+	bot.stateMu.RLock()
+	state := BotState{
+		SavedAt:         time.Now(),
+		StartTime:       bot.StartTime,
+		TotalBudget:     bot.TotalBudget,
+		AvailableBudget: bot.AvailableBudget,
+		NextPositionID:  bot.NextPositionID,
+		Positions:       append([]TradingPosition(nil), bot.Positions...),
+		CompletedTrades: append([]CompletedTrade(nil), bot.CompletedTrades...),
+		Stats:           bot.Stats,
+
+		DryRunOrders:      bot.DryRunOrders,
+		NextDryRunOrderID: bot.NextDryRunOrderID,
+	}
+	bot.stateMu.RUnlock()
+
+	// Concurrent dry-run buys may be adding simulated orders while the state is encoded
+	bot.dryRunMu.Lock()
+	data, err := json.MarshalIndent(state, "", "  ")
+	bot.dryRunMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding state: %v", err)
+	}
+
+	// Write to a temp file first so a crash mid-write never leaves a truncated state file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".bot_state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temp state file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing state: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing state file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing state file: %v", err)
+	}
+
+	return nil
+}
+
+// LoadState reads bot state from disk into the bot
+func (bot *TradingBot) LoadState(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var state BotState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("error parsing state file %s: %v", path, err)
+	}
+
+	bot.StartTime = state.StartTime
+	bot.TotalBudget = state.TotalBudget
+	bot.stateMu.Lock()
+	bot.AvailableBudget = state.AvailableBudget
+	bot.NextPositionID = state.NextPositionID
+	bot.Positions = state.Positions
+	bot.CompletedTrades = state.CompletedTrades
+	bot.Stats = state.Stats
+	bot.stateMu.Unlock()
+	bot.DryRunOrders = state.DryRunOrders
+	bot.NextDryRunOrderID = state.NextDryRunOrderID
+
+	return nil
+}
+
+// saveState persists state to the configured file, logging instead of failing on errors
+func (bot *TradingBot) saveState() {
+	if err := bot.SaveState(stateFilePath()); err != nil {
+		log.Printf("WARNING: Failed to save state: %v", err)
+	}
+}
+
+// restoreState loads saved positions and trades at startup, keeping the live exchange balance
+// as the available budget outside dry run. A missing or corrupt state file leaves the bot starting fresh.
+func (bot *TradingBot) restoreState(path string) {
+	liveBalance := bot.AvailableBudget
+
+	if err := bot.LoadState(path); err != nil {
+		if os.IsNotExist(err) {
+			logInfo.Printf("No saved state at %s - starting fresh\n", path)
+		} else {
+			log.Printf("WARNING: Could not restore state (%v) - starting fresh", err)
+		}
+		return
+	}
+
+	// Live sell orders may have filled while the bot was down. Book them now, before the budget is reset to
+	// the live balance below, which already includes their proceeds. Simulated orders can't be checked until
+	// the first cycle has loaded prices, so a dry run leaves them to that cycle's reconcile.
+	if !bot.DryRun && bot.hasActiveSellOrders() {
+		logInfo.Println("Checking for sell orders that filled while the bot was stopped...")
+		bot.reconcileSellOrders()
+	}
+
+	// Free USDT on the exchange already excludes funds tied up in open positions
+	invested := 0.0
+	maxID := 0
+	for _, pos := range bot.Positions {
+		invested += pos.InvestedAmount
+		if pos.ID > maxID {
+			maxID = pos.ID
+		}
+	}
+	for _, trade := range bot.CompletedTrades {
+		if trade.ID > maxID {
+			maxID = trade.ID
+		}
+	}
+
+	// A dry run has no exchange balance to resync with: DRY_RUN_BALANCE is only the starting paper money,
+	// so the saved budget is kept to carry the paper P/L and the funds already in open positions
+	if !bot.DryRun {
+		// Logically banked profit is still in the account unless it was transferred out
+		if bot.ProfitSkimEmail == "" {
+			liveBalance = math.Max(0, liveBalance-bot.Stats.BankedProfit)
+		}
+
+		bot.AvailableBudget = liveBalance
+		bot.TotalBudget = liveBalance + invested
+	}
+	if bot.NextPositionID <= maxID {
+		bot.NextPositionID = maxID + 1
+	}
+	if bot.Positions == nil {
+		bot.Positions = make([]TradingPosition, 0)
+	}
+	if bot.CompletedTrades == nil {
+		bot.CompletedTrades = make([]CompletedTrade, 0)
+	}
+	if bot.StartTime.IsZero() {
+		bot.StartTime = time.Now()
+	}
+
+	logInfo.Printf("Restored state from %s: %d open positions (%.2f USDT invested), %d completed trades\n",
+		path, len(bot.Positions), invested, len(bot.CompletedTrades))
+}
+
+// hasActiveSellOrders reports whether any open position has a resting sell order on Binance
+func (bot *TradingBot) hasActiveSellOrders() bool {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	for _, pos := range bot.Positions {
+		if pos.HasActiveSellOrder {
+			return true
+		}
+	}
+	return false
+}
+
+// getPositionsSnapshot returns a copy of the open positions, safe to use while buys run or the HTTP server reads
+func (bot *TradingBot) getPositionsSnapshot() []TradingPosition {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	positions := make([]TradingPosition, len(bot.Positions))
+	copy(positions, bot.Positions)
+	return positions
+}
+
+// setPositions replaces the open positions
+func (bot *TradingBot) setPositions(positions []TradingPosition) {
+	bot.stateMu.Lock()
+	defer bot.stateMu.Unlock()
+
+	bot.Positions = positions
+}
+
+// removePosition drops the position with the given ID
+func (bot *TradingBot) removePosition(id int) {
+	bot.stateMu.Lock()
+	defer bot.stateMu.Unlock()
+
+	bot.Positions = slices.DeleteFunc(slices.Clone(bot.Positions), func(pos TradingPosition) bool { return pos.ID == id })
+}
+
+// getAvailableBudget returns the USDT currently available for new buys
+func (bot *TradingBot) getAvailableBudget() float64 {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	return bot.AvailableBudget
+}
+
+// getStatsSnapshot returns a copy of the performance statistics
+func (bot *TradingBot) getStatsSnapshot() PaperTradingStats {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	return bot.Stats
+}
@@ -1,6 +1,10 @@
 package main
 
-import "time"
+import (
+	"net/http"
+	"sync"
+	"time"
+)
 
 // TradingPosition represents an active trading position
 type TradingPosition struct {
@@ -13,8 +17,23 @@ type TradingPosition struct {
 	BuyTime            time.Time
 	DropPercentage     float64 // The drop percentage when bought
 	CurrentValue       float64 // Current market value
-	SellOrderID        int64   // Binance sell order ID (0 if no order placed)
+	SellOrderID        int64   // Binance sell order ID (0 if no order placed); the target leg of an OCO
+	StopOrderID        int64   // Stop-limit leg of the OCO sell (0 if no OCO)
+	OCOOrderListID     int64   // Binance order list ID of the OCO sell (0 if no OCO)
 	HasActiveSellOrder bool    // Track if sell order is active
+	FeesPaid           float64 // Commission in USDT, including BNB commission converted at the BNB price
+	BaseFeesPaid       float64 // Commission taken from the bought quantity, in base asset units
+	BNBFeesPaid        float64 // Commission paid in BNB (fee discount enabled)
+	HighestPrice       float64 // Highest price seen since buying, for the trailing stop
+
+	Tranches []TrancheOrder // Open limit sells when scaling out with SELL_TRANCHES; SellOrderID is the first
+}
+
+// TrancheOrder is one resting limit sell for part of a position
+type TrancheOrder struct {
+	OrderID  int64
+	Price    float64
+	Quantity float64
 }
 
 // CompletedTrade represents a finished trade for performance tracking
@@ -25,6 +44,7 @@ type CompletedTrade struct {
 	SellPrice      float64
 	Quantity       float64
 	InvestedAmount float64
+	Fees           float64 // USDT value of commission on both sides, already reflected in Profit
 	Profit         float64
 	ProfitPercent  float64
 	BuyTime        time.Time
@@ -37,15 +57,17 @@ type PaperTradingStats struct {
 	TotalTrades     int
 	WinningTrades   int
 	LosingTrades    int
-	TotalProfit     float64
-	TotalLoss       float64
+	TotalProfit     float64 // Sum of winning trades
+	TotalLoss       float64 // Sum of losing trades as a positive amount
 	NetProfit       float64
-	WinRate         float64
+	WinRate         float64 // Percentage of winning trades
 	AverageProfit   float64
-	AverageLoss     float64
+	AverageLoss     float64 // Positive amount
 	LargestWin      float64
-	LargestLoss     float64
+	LargestLoss     float64 // Positive amount
 	AverageHoldTime time.Duration
+	BankedProfit    float64 // Realized profit skimmed out of the trading budget
+	TotalFees       float64 // Cumulative commission across completed trades, in USDT
 }
 
 // BinanceConfig holds API configuration for Binance
@@ -55,28 +77,73 @@ type BinanceConfig struct {
 	BaseURL   string // Mainnet URL
 }
 
+// OrderSide is the side of a Binance order
+type OrderSide string
+
+const (
+	SideBuy  OrderSide = "BUY"
+	SideSell OrderSide = "SELL"
+)
+
+// OrderType is a Binance order type
+type OrderType string
+
+const (
+	OrderTypeMarket        OrderType = "MARKET"
+	OrderTypeLimit         OrderType = "LIMIT"
+	OrderTypeLimitMaker    OrderType = "LIMIT_MAKER"     // Target leg of an OCO sell
+	OrderTypeStopLossLimit OrderType = "STOP_LOSS_LIMIT" // Stop leg of an OCO sell
+)
+
+// OrderStatus is the lifecycle state Binance reports for an order
+type OrderStatus string
+
+const (
+	StatusNew             OrderStatus = "NEW"
+	StatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	StatusFilled          OrderStatus = "FILLED"
+	StatusCanceled        OrderStatus = "CANCELED"
+	StatusRejected        OrderStatus = "REJECTED"
+	StatusExpired         OrderStatus = "EXPIRED"
+)
+
 // OrderResponse represents Binance order response
 type OrderResponse struct {
-	Symbol        string `json:"symbol"`
-	OrderID       int64  `json:"orderId"`
-	ClientOrderID string `json:"clientOrderId"`
-	TransactTime  int64  `json:"transactTime"`
-	Price         string `json:"price"`
-	OrigQty       string `json:"origQty"`
-	ExecutedQty   string `json:"executedQty"`
-	Status        string `json:"status"`
-	Type          string `json:"type"`
-	Side          string `json:"side"`
-	Fills         []struct {
-		Price           string `json:"price"`
-		Qty             string `json:"qty"`
-		Commission      string `json:"commission"`
-		CommissionAsset string `json:"commissionAsset"`
-	} `json:"fills"`
+	Symbol        string      `json:"symbol"`
+	OrderID       int64       `json:"orderId"`
+	ClientOrderID string      `json:"clientOrderId"`
+	TransactTime  int64       `json:"transactTime"`
+	Price         string      `json:"price"`
+	StopPrice     string      `json:"stopPrice,omitempty"`
+	OrderListID   int64       `json:"orderListId"` // -1 unless the order is part of an OCO
+	OrigQty       string      `json:"origQty"`
+	ExecutedQty   string      `json:"executedQty"`
+	QuoteQty      string      `json:"cummulativeQuoteQty"`
+	Status        OrderStatus `json:"status"`
+	Type          OrderType   `json:"type"`
+	Side          OrderSide   `json:"side"`
+	Fills         []Fill      `json:"fills"`
+}
+
+// OCOResponse represents the Binance response to placing an OCO order list
+type OCOResponse struct {
+	OrderListID     int64           `json:"orderListId"`
+	ListStatusType  string          `json:"listStatusType"`
+	ListOrderStatus string          `json:"listOrderStatus"`
+	OrderReports    []OrderResponse `json:"orderReports"`
+}
+
+// Fill represents a single trade execution within a Binance order
+type Fill struct {
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
 }
 
 // AccountInfo represents Binance account information
 type AccountInfo struct {
+	CanTrade bool `json:"canTrade"`
 	Balances []struct {
 		Asset  string `json:"asset"`
 		Free   string `json:"free"`
@@ -84,18 +151,142 @@ type AccountInfo struct {
 	} `json:"balances"`
 }
 
+// DustTransferResponse is the Binance response to converting small balances to BNB
+type DustTransferResponse struct {
+	TotalServiceCharge string `json:"totalServiceCharge"`
+	TotalTransfered    string `json:"totalTransfered"`
+	TransferResult     []struct {
+		Amount              string `json:"amount"`
+		FromAsset           string `json:"fromAsset"`
+		ServiceChargeAmount string `json:"serviceChargeAmount"`
+		TransferedAmount    string `json:"transferedAmount"`
+		TranID              int64  `json:"tranId"`
+	} `json:"transferResult"`
+}
+
 // TradingBot represents our trading bot configuration
 type TradingBot struct {
-	TotalBudget      float64
-	AvailableBudget  float64 // Track remaining budget
-	InvestmentAmount float64 // Amount to invest per trade (5 EUR)
-	Positions        []TradingPosition
-	CompletedTrades  []CompletedTrade
-	WatchList        []OptimizedTicker
-	Stats            PaperTradingStats
-	NextPositionID   int           // For unique position tracking
-	StartTime        time.Time     // When trading started
-	BinanceConfig    BinanceConfig // API configuration
+	TotalBudget       float64
+	AvailableBudget   float64 // Track remaining budget
+	InvestmentAmount  float64 // Amount to invest per trade (5 EUR)
+	InvestmentMode    string  // "fixed" (InvestmentAmount per trade) or "percent" (InvestmentPercent of available budget)
+	InvestmentPercent float64 // Percent of the available budget per trade in percent mode
+	SizingMode        string  // "flat" (same amount for every drop) or "drop" (scaled up with the drop depth)
+	DropSizingMax     float64 // Multiplier on the trade amount at the deep end of the buy band in drop mode
+	DropSizingCurve   float64 // Exponent shaping the scale-up: 1 is linear, >1 saves the extra for the deepest drops
+	Positions         []TradingPosition
+	CompletedTrades   []CompletedTrade
+	WatchList         []OptimizedTicker
+	Stats             PaperTradingStats
+	NextPositionID    int            // For unique position tracking
+	StartTime         time.Time      // When trading started
+	Binance           *BinanceClient // Binance API client
+	LastCycleTime     time.Time      // When the last trading cycle completed successfully
+	LastCycleError    string         // Error from the most recent cycle ("" if it succeeded)
+	LastCycleDuration time.Duration  // How long the most recent cycle took
+	CyclesRun         int            // Trading cycles run since startup
+	CycleErrors       int            // Trading cycles that failed since startup
+	HealthStaleAfter  time.Duration  // Max age of the last successful cycle before /healthz fails
+	ExitOrderType     string         // "limit" (resting sell order) or "market" (monitored market sell)
+	UseOCO            bool           // Place the target and stop-loss together as a Binance OCO sell
+	BuyOrderType      string         // "market" or "limit" (limit buy just above the price, cancelled if unfilled)
+	BuyLimitSlippage  float64        // Percent above the current price for limit buys
+	SellRounding      RoundingMode   // How target sell prices are rounded to the tick size
+	BuyLimitTimeout   time.Duration  // How long a limit buy may rest before it is cancelled
+	SellPlaceTimeout  time.Duration  // Max time to wait for a buy to show up as free balance before selling
+	SellMaxRetries    int            // Sell placement attempts after a buy
+	SellRetryBackoff  time.Duration  // Base delay for exponential backoff between attempts
+
+	VolumeSpikeMultiplier float64              // Skip buys when volume exceeds this multiple of its average
+	VolumeHistory         map[string][]float64 // Recent 24h volume samples per symbol
+
+	ProfitSkimPercent float64 // Percent of each realized gain banked out of the trading budget
+	ProfitSkimEmail   string  // Sub-account email to transfer banked profit to ("" = logical only)
+
+	TelegramBotToken  string        // Bot token for trade alerts ("" disables Telegram)
+	TelegramChatID    string        // Chat that receives trade alerts
+	DiscordWebhookURL string        // Webhook for trade and error alerts ("" disables Discord)
+	ReportInterval    time.Duration // How often a performance report is sent (0 disables reports)
+	CycleRetries      int           // Retries with backoff after a failed cycle before waiting for the next one
+	WatchlistSize     int           // Coins analyzed each cycle
+	CMCFetchLimit     int           // CoinMarketCap listings fetched to fill the watchlist after filtering
+
+	DataSource         string          // Market data provider: "cmc", "binance" or "coingecko"
+	MinVolume24hUSD    float64         // Skip coins trading less than this in 24h (0 = no minimum)
+	StablecoinSymbols  map[string]bool // Base assets never traded (STABLECOIN_SYMBOLS)
+	SymbolBlacklist    map[string]bool // Base assets never bought (SYMBOL_BLACKLIST)
+	SymbolWhitelist    map[string]bool // When non-empty, the only base assets bought (SYMBOL_WHITELIST)
+	TradeableSymbols   map[string]bool // USDT pairs trading on Binance (cached exchange info)
+	TradeableSymbolsAt time.Time       // When TradeableSymbols was last fetched
+	ExchangeInfoTTL    time.Duration   // How long cached exchange info stays valid
+
+	ProfitTargetPercent float64 // Net profit target per trade after fees
+	TakerFeePercent     float64 // Fee rate for taker orders (market buys/sells)
+	MakerFeePercent     float64 // Fee rate for maker orders (resting limit sells)
+	BNBFeeDiscount      bool    // Fees are paid in BNB at a discount
+
+	ExplainMode bool // Log the decision and reason code for every coin
+	PreviewMode bool // Report planned buys without placing any orders
+
+	BuyDropMin          float64       // Buy when the 24h change is at or below this (e.g. -5)
+	BuyDropMax          float64       // ...and above this (e.g. -10)
+	SafetyDropLimit     float64       // Never buy at or below this drop (e.g. -11)
+	StopLossPercent     float64       // Market-sell when price falls this far below the buy price (0 = disabled)
+	TrailingStopPercent float64       // Market-sell when price falls this far below its high since buying (0 = disabled)
+	MaxHold             time.Duration // Market-sell positions held longer than this, whatever the P/L (0 = disabled)
+	MaxOpenPositions    int           // Maximum number of positions held at once
+	BuyConcurrency      int           // Buy orders placed in parallel per cycle
+	RebuyCooldown       time.Duration // Wait after selling a symbol before buying it again (0 = disabled)
+	MaxTradeUSDT        float64       // Hard cap on a single buy in USDT (0 = no cap)
+	MaxTotalExposure    float64       // Cap on the total invested across open positions in USDT (0 = no cap)
+	MaxDailyLoss        float64       // Realized loss since midnight that halts new buys for the day (0 = no limit)
+	MinNetProfit        float64       // Smallest net profit in USDT a target sell may make after fees
+	SellTranches        []sellTranche // Scale out with several limit sells instead of one (empty = single target sell)
+	dailyHaltAlerted    time.Time     // Midnight of the day the daily loss halt was last announced
+
+	WeightLimit int // Binance request weight allowed per minute
+	UsedWeight  int // Latest X-MBX-USED-WEIGHT-1M reported by Binance
+
+	DryRun            bool                    // Simulate all orders instead of sending them to Binance
+	DryRunOrders      map[int64]OrderResponse // Resting simulated orders by ID
+	NextDryRunOrderID int64                   // For unique simulated order IDs
+	SimulatedSlippage float64                 // Percent simulated market fills are moved against us (dry run and backtests)
+
+	RunOnce     bool // Run a single cycle and exit, for scheduling with cron or systemd timers
+	AutoConfirm bool // Start live trading without asking for CONFIRM on stdin
+
+	MaintenanceCooldown time.Duration // Trading pause after Binance answers 503, e.g. during maintenance (0 = never pause)
+
+	httpClient *http.Client // Shared by every request so connections are reused
+
+	// mu guards what the HTTP server reads: status and the cycle results (LastCycleTime, LastCycleError,
+	// CyclesRun, CycleErrors). stateMu guards the trading state below. The two are never held together:
+	// snapshot the trading state first, then lock mu to publish it.
+	mu     sync.RWMutex
+	status BotStatus // Snapshot served by /status, refreshed after every cycle
+
+	exchangeInfoMu sync.Mutex                     // Guards symbolFilters, TradeableSymbols and TradeableSymbolsAt
+	symbolFilters  map[string]cachedSymbolFilters // Symbol filters cached from exchange info
+
+	stateMu        sync.RWMutex // Guards Positions, AvailableBudget, Stats, CompletedTrades, NextPositionID and the pending buys
+	pendingBuys    int          // Buys reserved but not yet filled or released
+	pendingBuyUSDT float64      // USDT reserved by pending buys
+	unsentBuyUSDT  float64      // Part of pendingBuyUSDT whose orders haven't been sent to Binance yet
+
+	dryRunMu sync.Mutex // Guards DryRunOrders and NextDryRunOrderID
+
+	bnbPriceMu    sync.Mutex
+	bnbPriceCache float64 // BNB/USDT price for valuing BNB fees, reset every cycle
+
+	positionPrices map[string]float64 // Ticker prices for open positions outside the watchlist, reset every cycle
+
+	weightWarnedWindow int64 // Minute window (server time) the request weight warning was last logged for
+
+	cmcExpanded bool // CMC_FETCH_LIMIT left the watchlist short, so cycles fetch the expanded top directly
+
+	maintenanceMu    sync.Mutex
+	maintenanceSince time.Time // When Binance was first seen unavailable (zero when trading normally)
+	maintenanceUntil time.Time // Trading stays paused until then
 }
 
 // Ticker24hr represents the 24hr ticker statistics from Binance API
@@ -127,6 +318,7 @@ type OptimizedTicker struct {
 	LastPrice          float64
 	PriceChangePercent float64
 	PercentChange24h   float64
+	Volume24h          float64 // 24h trading volume in USD
 }
 
 // CoinMarketCapResponse represents the response from CoinMarketCap API
@@ -138,21 +330,24 @@ type CoinMarketCapResponse struct {
 		Elapsed      int    `json:"elapsed"`
 		CreditCount  int    `json:"credit_count"`
 	} `json:"status"`
-	Data []struct {
-		ID     int    `json:"id"`
-		Name   string `json:"name"`
-		Symbol string `json:"symbol"`
-		Slug   string `json:"slug"`
-		Quote  struct {
-			USD struct {
-				Price            float64 `json:"price"`
-				Volume24h        float64 `json:"volume_24h"`
-				PercentChange1h  float64 `json:"percent_change_1h"`
-				PercentChange24h float64 `json:"percent_change_24h"`
-				PercentChange7d  float64 `json:"percent_change_7d"`
-				MarketCap        float64 `json:"market_cap"`
-				LastUpdated      string  `json:"last_updated"`
-			} `json:"USD"`
-		} `json:"quote"`
-	} `json:"data"`
+	Data []CoinMarketCapCoin `json:"data"`
+}
+
+// CoinMarketCapCoin is a single listing with its USD quote
+type CoinMarketCapCoin struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	Slug   string `json:"slug"`
+	Quote  struct {
+		USD struct {
+			Price            float64 `json:"price"`
+			Volume24h        float64 `json:"volume_24h"`
+			PercentChange1h  float64 `json:"percent_change_1h"`
+			PercentChange24h float64 `json:"percent_change_24h"`
+			PercentChange7d  float64 `json:"percent_change_7d"`
+			MarketCap        float64 `json:"market_cap"`
+			LastUpdated      string  `json:"last_updated"`
+		} `json:"USD"`
+	} `json:"quote"`
 }
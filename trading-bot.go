@@ -1,228 +1,699 @@
 package main
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
-	"net/url"
+	"log/slog"
+	"maps"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// defaultBinanceBaseURL is the Binance spot mainnet REST endpoint
+const defaultBinanceBaseURL = "https://api.binance.com"
+
+// binanceBaseURL returns the Binance REST endpoint, e.g. https://testnet.binance.vision for the spot testnet
+func binanceBaseURL() string {
+	if baseURL := strings.TrimSpace(os.Getenv("BINANCE_BASE_URL")); baseURL != "" {
+		return strings.TrimRight(baseURL, "/")
+	}
+	return defaultBinanceBaseURL
+}
+
+// Exit order types for taking profit
+const (
+	exitOrderLimit  = "limit"  // Resting GTC limit sell at the target price
+	exitOrderMarket = "market" // Bot monitors price and market-sells when the target is hit
+)
+
+// Position sizing modes (SIZING_MODE)
+const (
+	sizingModeFlat = "flat" // Same trade amount for every drop in the buy band
+	sizingModeDrop = "drop" // Trade amount scaled up with the depth of the drop
+)
+
+// Buy order types (BUY_ORDER_TYPE)
+const (
+	buyOrderMarket = "market" // Market buy spending the investment amount
+	buyOrderLimit  = "limit"  // Limit buy capped slightly above the current price
+)
+
+// Per-trade investment sizing modes (INVESTMENT_MODE)
+const (
+	investmentModeFixed   = "fixed"   // Flat InvestmentAmount per trade
+	investmentModePercent = "percent" // INVESTMENT_PERCENT of the available budget per trade
+)
+
+// Rolling volume baseline used for spike detection (one sample per cycle)
+const (
+	minVolumeSamples = 3  // Samples required before spikes are checked
+	maxVolumeSamples = 24 // Samples kept per symbol
+)
+
+// ocoStopLimitBufferPercent places an OCO's stop-limit price this far below its stop trigger so it still fills on a fast drop
+const ocoStopLimitBufferPercent = 0.5
+
+// bnbFeeDiscountPercent is Binance's spot fee discount when paying fees with BNB
+const bnbFeeDiscountPercent = 25.0
+
+// Trading cycle interval bounds (CYCLE_INTERVAL_MINUTES)
+const (
+	defaultCycleInterval = 60 * time.Minute
+	maxCycleMinutes      = 24 * 60 // Anything longer is almost certainly a typo
+)
+
+// Backoff for retrying a failed trading cycle before the next scheduled one (CYCLE_RETRY_ATTEMPTS)
+const (
+	cycleRetryBaseDelay = 30 * time.Second
+	cycleRetryMaxDelay  = 5 * time.Minute
+)
+
+// cycleRetryDelay returns the wait before retry number attempt (from 0): 30s, 1m, 2m, 4m, then capped at 5m
+func cycleRetryDelay(attempt int) time.Duration {
+	if attempt >= 4 {
+		return cycleRetryMaxDelay
+	}
+	return min(cycleRetryBaseDelay<<attempt, cycleRetryMaxDelay)
+}
+
 // NewTradingBot creates a new trading bot instance
 func NewTradingBot(budget float64) (*TradingBot, error) {
 	// Initialize Binance configuration
 	binanceConfig := BinanceConfig{
 		APIKey:    os.Getenv("BINANCE_API_KEY"),
 		SecretKey: os.Getenv("BINANCE_SECRET_KEY"),
-		BaseURL:   "https://api.binance.com",
+		BaseURL:   binanceBaseURL(),
 	}
 
-	// Check if API keys are provided
-	if binanceConfig.APIKey == "" || binanceConfig.SecretKey == "" {
+	dryRun := getEnvBool("DRY_RUN", false)
+
+	// Check if API keys are provided (dry run simulates every order, so keys are optional)
+	if !dryRun && (binanceConfig.APIKey == "" || binanceConfig.SecretKey == "") {
 		return nil, fmt.Errorf("BINANCE API KEYS REQUIRED!")
 	}
+	if !dryRun {
+		if err := validateBinanceKeys(binanceConfig.APIKey, binanceConfig.SecretKey); err != nil {
+			return nil, fmt.Errorf("invalid Binance API keys: %v", err)
+		}
+	}
+
+	if dryRun {
+		logInfo.Println("DRY RUN MODE: orders are simulated against live prices - no real money is used")
+	} else {
+		logInfo.Println("Starting with real trading - monitor closely!")
+	}
+
+	// Health check fails if no cycle succeeded within this window (default 2x cycle interval)
+	defaultStaleMinutes := int(2 * defaultCycleInterval / time.Minute)
+	staleMinutes := getEnvInt("HEALTH_STALE_MINUTES", defaultStaleMinutes)
+	if staleMinutes <= 0 {
+		log.Printf("WARNING: HEALTH_STALE_MINUTES must be positive, using default %d", defaultStaleMinutes)
+		staleMinutes = defaultStaleMinutes
+	}
+
+	maintenanceCooldownMinutes := getEnvInt("BINANCE_MAINTENANCE_COOLDOWN_MINUTES", defaultMaintenanceCooldownMinutes)
+	if maintenanceCooldownMinutes < 0 {
+		log.Printf("WARNING: BINANCE_MAINTENANCE_COOLDOWN_MINUTES cannot be negative, using default %d", defaultMaintenanceCooldownMinutes)
+		maintenanceCooldownMinutes = defaultMaintenanceCooldownMinutes
+	}
+
+	cycleRetries := getEnvInt("CYCLE_RETRY_ATTEMPTS", 4)
+	if cycleRetries < 0 {
+		log.Printf("WARNING: CYCLE_RETRY_ATTEMPTS cannot be negative, disabling cycle retries")
+		cycleRetries = 0
+	}
+
+	// How many coins to analyze, and how many CMC listings to fetch so enough survive the filters
+	watchlistSize := getEnvInt("WATCHLIST_SIZE", defaultWatchlistSize)
+	if watchlistSize < 1 || watchlistSize > maxCMCFetchLimit {
+		log.Printf("WARNING: WATCHLIST_SIZE must be between 1 and %d, using default %d", maxCMCFetchLimit, defaultWatchlistSize)
+		watchlistSize = defaultWatchlistSize
+	}
+	cmcFetchLimit := getEnvInt("CMC_FETCH_LIMIT", max(defaultCMCFetchLimit, watchlistSize))
+	if cmcFetchLimit < watchlistSize || cmcFetchLimit > maxCMCFetchLimit {
+		fallback := max(defaultCMCFetchLimit, watchlistSize)
+		log.Printf("WARNING: CMC_FETCH_LIMIT must be between WATCHLIST_SIZE (%d) and %d, using %d",
+			watchlistSize, maxCMCFetchLimit, fallback)
+		cmcFetchLimit = fallback
+	}
+
+	reportIntervalHours := getEnvInt("REPORT_INTERVAL_HOURS", 0)
+	if reportIntervalHours < 0 {
+		log.Printf("WARNING: REPORT_INTERVAL_HOURS cannot be negative, disabling reports")
+		reportIntervalHours = 0
+	}
+
+	exitOrderType := strings.ToLower(strings.TrimSpace(os.Getenv("EXIT_ORDER_TYPE")))
+	switch exitOrderType {
+	case exitOrderLimit, exitOrderMarket:
+	case "":
+		exitOrderType = exitOrderLimit
+	default:
+		log.Printf("WARNING: Invalid EXIT_ORDER_TYPE=%q (expected limit or market), using limit", exitOrderType)
+		exitOrderType = exitOrderLimit
+	}
+
+	buyOrderType := strings.ToLower(strings.TrimSpace(os.Getenv("BUY_ORDER_TYPE")))
+	switch buyOrderType {
+	case buyOrderMarket, buyOrderLimit:
+	case "":
+		buyOrderType = buyOrderMarket
+	default:
+		log.Printf("WARNING: Invalid BUY_ORDER_TYPE=%q (expected market or limit), using market", buyOrderType)
+		buyOrderType = buyOrderMarket
+	}
+	buyLimitSlippage := getEnvFloat("BUY_LIMIT_SLIPPAGE_PERCENT", 0.5)
+	if buyLimitSlippage < 0 || buyLimitSlippage >= 100 {
+		log.Printf("WARNING: BUY_LIMIT_SLIPPAGE_PERCENT must be between 0 and 100, using default 0.5")
+		buyLimitSlippage = 0.5
+	}
+	simulatedSlippage := getEnvFloat("SIMULATED_SLIPPAGE_PERCENT", 0)
+	if simulatedSlippage < 0 || simulatedSlippage >= 100 {
+		log.Printf("WARNING: SIMULATED_SLIPPAGE_PERCENT must be between 0 and 100, disabling simulated slippage")
+		simulatedSlippage = 0
+	}
+	sellRounding := RoundUp
+	if raw := os.Getenv("SELL_PRICE_ROUNDING"); raw != "" {
+		mode, ok := parseRoundingMode(raw)
+		if !ok {
+			log.Printf("WARNING: Invalid SELL_PRICE_ROUNDING=%q (expected up, down or nearest), using up", raw)
+			mode = RoundUp
+		}
+		sellRounding = mode
+	}
+	buyLimitTimeoutSeconds := getEnvInt("BUY_LIMIT_TIMEOUT_SECONDS", 10)
+	if buyLimitTimeoutSeconds < 1 {
+		log.Printf("WARNING: BUY_LIMIT_TIMEOUT_SECONDS must be at least 1, using default 10")
+		buyLimitTimeoutSeconds = 10
+	}
+
+	// Buy-then-sell settlement: balance poll timeout, sell attempts, and exponential backoff base
+	if os.Getenv("SELL_SETTLE_DELAY_SECONDS") != "" {
+		log.Printf("WARNING: SELL_SETTLE_DELAY_SECONDS is no longer used - the bot polls the balance for up to SELL_PLACE_TIMEOUT_SECONDS")
+	}
+	placeTimeoutSeconds := getEnvInt("SELL_PLACE_TIMEOUT_SECONDS", 30)
+	if placeTimeoutSeconds < 1 {
+		log.Printf("WARNING: SELL_PLACE_TIMEOUT_SECONDS must be at least 1, using default 30")
+		placeTimeoutSeconds = 30
+	}
+	sellMaxRetries := getEnvInt("SELL_MAX_RETRIES", 3)
+	if sellMaxRetries < 1 {
+		log.Printf("WARNING: SELL_MAX_RETRIES must be at least 1, using default 3")
+		sellMaxRetries = 3
+	}
+	backoffSeconds := getEnvInt("SELL_RETRY_BACKOFF_SECONDS", 2)
+	if backoffSeconds < 1 {
+		log.Printf("WARNING: SELL_RETRY_BACKOFF_SECONDS must be at least 1, using default 2")
+		backoffSeconds = 2
+	}
+
+	// Skip buys when 24h volume is this many times its recent average (0 disables)
+	volumeSpikeMultiplier := getEnvFloat("VOLUME_SPIKE_MULTIPLIER", 3.0)
+	if volumeSpikeMultiplier < 0 {
+		log.Printf("WARNING: VOLUME_SPIKE_MULTIPLIER cannot be negative, using default 3.0")
+		volumeSpikeMultiplier = 3.0
+	}
+
+	// Fraction of each realized gain banked out of the trading budget (0 disables)
+	profitSkimPercent := getEnvFloat("PROFIT_SKIM_PCT", 0)
+	if profitSkimPercent < 0 || profitSkimPercent > 100 {
+		log.Printf("WARNING: PROFIT_SKIM_PCT must be between 0 and 100, disabling profit skim")
+		profitSkimPercent = 0
+	}
+
+	// Profit target is net of fees; fee rates are percentages (Binance default tier: 0.1%)
+	// PROFIT_TARGET_PCT is accepted as an older alias of PROFIT_TARGET_PERCENT
+	profitTargetPercent := getEnvFloat("PROFIT_TARGET_PERCENT", getEnvFloat("PROFIT_TARGET_PCT", 5.0))
+	if profitTargetPercent <= 0 {
+		log.Printf("WARNING: PROFIT_TARGET_PERCENT must be positive, using default 5.0")
+		profitTargetPercent = 5.0
+	}
+	takerFeePercent := getEnvFloat("TAKER_FEE_PCT", 0.1)
+	makerFeePercent := getEnvFloat("MAKER_FEE_PCT", 0.1)
+	if takerFeePercent < 0 || takerFeePercent >= 100 || makerFeePercent < 0 || makerFeePercent >= 100 {
+		log.Printf("WARNING: Fee percentages must be between 0 and 100, using default 0.1")
+		takerFeePercent, makerFeePercent = 0.1, 0.1
+	}
+
+	// Buy band and safety cutoff as negative 24h changes; positive values are treated as drops
+	buyDropMin := -math.Abs(getEnvFloat("BUY_DROP_MIN", -5.0))
+	buyDropMax := -math.Abs(getEnvFloat("BUY_DROP_MAX", -10.0))
+	safetyDropLimit := -math.Abs(getEnvFloat("SAFETY_DROP_LIMIT", -11.0))
+	if !(buyDropMin > buyDropMax && buyDropMax >= safetyDropLimit) {
+		log.Printf("WARNING: Drop thresholds must satisfy BUY_DROP_MIN > BUY_DROP_MAX >= SAFETY_DROP_LIMIT "+
+			"(got %.2f / %.2f / %.2f), using defaults -5 / -10 / -11", buyDropMin, buyDropMax, safetyDropLimit)
+		buyDropMin, buyDropMax, safetyDropLimit = -5.0, -10.0, -11.0
+	}
+	logInfo.Printf("Buy band: %.2f%% to %.2f%% | Safety limit: %.2f%%\n", buyDropMin, buyDropMax, safetyDropLimit)
+
+	// Stop-loss below the buy price in percent (0 disables)
+	stopLossPercent := getEnvFloat("STOP_LOSS_PERCENT", 0)
+	if stopLossPercent < 0 || stopLossPercent >= 100 {
+		log.Printf("WARNING: STOP_LOSS_PERCENT must be between 0 and 100, disabling stop-loss")
+		stopLossPercent = 0
+	}
+
+	// Trailing stop below the highest price seen since buying, in percent (0 disables)
+	trailingStopPercent := getEnvFloat("TRAILING_STOP_PERCENT", 0)
+	if trailingStopPercent < 0 || trailingStopPercent >= 100 {
+		log.Printf("WARNING: TRAILING_STOP_PERCENT must be between 0 and 100, disabling trailing stop")
+		trailingStopPercent = 0
+	}
+	if trailingStopPercent > 0 && stopLossPercent > 0 {
+		log.Printf("WARNING: STOP_LOSS_PERCENT and TRAILING_STOP_PERCENT are both set - using the trailing stop only")
+		stopLossPercent = 0
+	}
+
+	// Force out positions that never reach their target so they stop tying up capital (0 disables)
+	maxHoldHours := getEnvInt("MAX_HOLD_HOURS", 0)
+	if maxHoldHours < 0 {
+		log.Printf("WARNING: MAX_HOLD_HOURS cannot be negative, disabling forced exits")
+		maxHoldHours = 0
+	}
+
+	exchangeInfoTTLMinutes := getEnvInt("EXCHANGE_INFO_TTL_MINUTES", int(defaultExchangeInfoTTL/time.Minute))
+	if exchangeInfoTTLMinutes <= 0 {
+		log.Printf("WARNING: EXCHANGE_INFO_TTL_MINUTES must be positive, using default %d", int(defaultExchangeInfoTTL/time.Minute))
+		exchangeInfoTTLMinutes = int(defaultExchangeInfoTTL / time.Minute)
+	}
+
+	// Illiquid coins slip badly on market orders (0 disables)
+	minVolume24h := getEnvFloat("MIN_24H_VOLUME_USD", 0)
+	if minVolume24h < 0 {
+		log.Printf("WARNING: MIN_24H_VOLUME_USD cannot be negative, disabling the volume filter")
+		minVolume24h = 0
+	}
+
+	dataSource, ok := parseDataSource(os.Getenv("DATA_SOURCE"))
+	if !ok {
+		log.Printf("WARNING: Invalid DATA_SOURCE=%q (expected cmc, binance or coingecko), using cmc", os.Getenv("DATA_SOURCE"))
+	}
+
+	investmentMode := strings.ToLower(strings.TrimSpace(os.Getenv("INVESTMENT_MODE")))
+	switch investmentMode {
+	case investmentModeFixed, investmentModePercent:
+	case "":
+		investmentMode = investmentModeFixed
+	default:
+		log.Printf("WARNING: Invalid INVESTMENT_MODE=%q (expected fixed or percent), using fixed", investmentMode)
+		investmentMode = investmentModeFixed
+	}
+	investmentPercent := getEnvFloat("INVESTMENT_PERCENT", 10.0)
+	if investmentPercent <= 0 || investmentPercent > 100 {
+		log.Printf("WARNING: INVESTMENT_PERCENT must be between 0 and 100, using default 10")
+		investmentPercent = 10.0
+	}
+
+	sizingMode := strings.ToLower(strings.TrimSpace(os.Getenv("SIZING_MODE")))
+	switch sizingMode {
+	case sizingModeFlat, sizingModeDrop:
+	case "":
+		sizingMode = sizingModeFlat
+	default:
+		log.Printf("WARNING: Invalid SIZING_MODE=%q (expected flat or drop), using flat", sizingMode)
+		sizingMode = sizingModeFlat
+	}
+	dropSizingMax := getEnvFloat("DROP_SIZING_MAX_MULTIPLIER", 2.0)
+	if dropSizingMax < 1 {
+		log.Printf("WARNING: DROP_SIZING_MAX_MULTIPLIER must be at least 1, using default 2")
+		dropSizingMax = 2.0
+	}
+	dropSizingCurve := getEnvFloat("DROP_SIZING_CURVE", 1.0)
+	if dropSizingCurve <= 0 {
+		log.Printf("WARNING: DROP_SIZING_CURVE must be positive, using default 1 (linear)")
+		dropSizingCurve = 1.0
+	}
+
+	weightLimit := getEnvInt("BINANCE_WEIGHT_LIMIT", defaultWeightLimit)
+	if weightLimit <= 0 {
+		log.Printf("WARNING: BINANCE_WEIGHT_LIMIT must be positive, using default %d", defaultWeightLimit)
+		weightLimit = defaultWeightLimit
+	}
+
+	// OCO sells need a resting limit target and a fixed stop to pair it with
+	useOCO := getEnvBool("USE_OCO", false)
+	if useOCO && (exitOrderType != exitOrderLimit || stopLossPercent <= 0) {
+		log.Printf("WARNING: USE_OCO needs EXIT_ORDER_TYPE=limit and STOP_LOSS_PERCENT (not a trailing stop) - placing plain limit sells")
+		useOCO = false
+	}
+
+	// Tranches are separate resting limit sells, so they can't be combined with OCO or market exits
+	sellTranches, err := parseSellTranches(os.Getenv("SELL_TRANCHES"))
+	if err != nil {
+		log.Printf("WARNING: Invalid SELL_TRANCHES (%v), placing a single target sell", err)
+		sellTranches = nil
+	}
+	if len(sellTranches) > 0 && (exitOrderType != exitOrderLimit || useOCO) {
+		log.Printf("WARNING: SELL_TRANCHES needs EXIT_ORDER_TYPE=limit without USE_OCO - placing a single target sell")
+		sellTranches = nil
+	}
+
+	buyConcurrency := getEnvInt("BUY_CONCURRENCY", 3)
+	if buyConcurrency < 1 {
+		log.Printf("WARNING: BUY_CONCURRENCY must be at least 1, using default 3")
+		buyConcurrency = 3
+	}
+
+	maxOpenPositions := getEnvInt("MAX_OPEN_POSITIONS", 5)
+	if maxOpenPositions < 1 {
+		log.Printf("WARNING: MAX_OPEN_POSITIONS must be at least 1, using default 5")
+		maxOpenPositions = 5
+	}
+
+	rebuyCooldownMinutes := getEnvInt("REBUY_COOLDOWN_MINUTES", 0)
+	if rebuyCooldownMinutes < 0 {
+		log.Printf("WARNING: REBUY_COOLDOWN_MINUTES cannot be negative, disabling the rebuy cooldown")
+		rebuyCooldownMinutes = 0
+	}
+
+	minNetProfit := getEnvFloat("MIN_NET_PROFIT_USDT", 0)
+	if minNetProfit < 0 {
+		log.Printf("WARNING: MIN_NET_PROFIT_USDT cannot be negative, using 0")
+		minNetProfit = 0
+	}
 
-	fmt.Println("Starting with real trading - monitor closely!")
+	// Risk caps in USDT (0 disables)
+	maxTradeUSDT := getEnvFloat("MAX_TRADE_USDT", 0)
+	if maxTradeUSDT < 0 {
+		log.Printf("WARNING: MAX_TRADE_USDT cannot be negative, disabling the per-trade cap")
+		maxTradeUSDT = 0
+	}
+	maxTotalExposure := getEnvFloat("MAX_TOTAL_EXPOSURE_USDT", 0)
+	if maxTotalExposure < 0 {
+		log.Printf("WARNING: MAX_TOTAL_EXPOSURE_USDT cannot be negative, disabling the exposure cap")
+		maxTotalExposure = 0
+	}
+	maxDailyLoss := getEnvFloat("MAX_DAILY_LOSS_USDT", 0)
+	if maxDailyLoss < 0 {
+		log.Printf("WARNING: MAX_DAILY_LOSS_USDT cannot be negative, disabling the daily loss limit")
+		maxDailyLoss = 0
+	}
 
+	httpClient := newHTTPClient()
 	bot := &TradingBot{
-		TotalBudget:      budget,
-		AvailableBudget:  budget,
-		InvestmentAmount: 7.0, // 7 USDT per trade as specified in strategy
-		Positions:        make([]TradingPosition, 0),
-		CompletedTrades:  make([]CompletedTrade, 0),
-		WatchList:        make([]OptimizedTicker, 0),
-		Stats:            PaperTradingStats{},
-		NextPositionID:   1,
-		StartTime:        time.Now(),
-		BinanceConfig:    binanceConfig,
+		TotalBudget:       budget,
+		AvailableBudget:   budget,
+		InvestmentAmount:  7.0, // 7 USDT per trade as specified in strategy
+		InvestmentMode:    investmentMode,
+		InvestmentPercent: investmentPercent,
+		SizingMode:        sizingMode,
+		DropSizingMax:     dropSizingMax,
+		DropSizingCurve:   dropSizingCurve,
+		Positions:         make([]TradingPosition, 0),
+		CompletedTrades:   make([]CompletedTrade, 0),
+		WatchList:         make([]OptimizedTicker, 0),
+		Stats:             PaperTradingStats{},
+		NextPositionID:    1,
+		StartTime:         time.Now(),
+		Binance:           NewBinanceClient(binanceConfig, httpClient),
+		httpClient:        httpClient,
+		HealthStaleAfter:  time.Duration(staleMinutes) * time.Minute,
+		ExitOrderType:     exitOrderType,
+		UseOCO:            useOCO,
+		BuyOrderType:      buyOrderType,
+		BuyLimitSlippage:  buyLimitSlippage,
+		SellRounding:      sellRounding,
+		BuyLimitTimeout:   time.Duration(buyLimitTimeoutSeconds) * time.Second,
+		SellPlaceTimeout:  time.Duration(placeTimeoutSeconds) * time.Second,
+		SellMaxRetries:    sellMaxRetries,
+		SellRetryBackoff:  time.Duration(backoffSeconds) * time.Second,
+
+		VolumeSpikeMultiplier: volumeSpikeMultiplier,
+		VolumeHistory:         make(map[string][]float64),
+
+		ProfitSkimPercent: profitSkimPercent,
+		ProfitSkimEmail:   strings.TrimSpace(os.Getenv("PROFIT_SKIM_TRANSFER_EMAIL")),
+
+		TelegramBotToken:  strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN")),
+		TelegramChatID:    strings.TrimSpace(os.Getenv("TELEGRAM_CHAT_ID")),
+		DiscordWebhookURL: strings.TrimSpace(os.Getenv("DISCORD_WEBHOOK_URL")),
+		ReportInterval:    time.Duration(reportIntervalHours) * time.Hour,
+		CycleRetries:      cycleRetries,
+		WatchlistSize:     watchlistSize,
+		CMCFetchLimit:     cmcFetchLimit,
+
+		MinVolume24hUSD:   minVolume24h,
+		StablecoinSymbols: getEnvSymbolSet("STABLECOIN_SYMBOLS", defaultStablecoins),
+		SymbolBlacklist:   getEnvBaseAssets("SYMBOL_BLACKLIST"),
+		SymbolWhitelist:   getEnvBaseAssets("SYMBOL_WHITELIST"),
+		ExchangeInfoTTL:   time.Duration(exchangeInfoTTLMinutes) * time.Minute,
+		DataSource:        dataSource,
+
+		ProfitTargetPercent: profitTargetPercent,
+		TakerFeePercent:     takerFeePercent,
+		MakerFeePercent:     makerFeePercent,
+		BNBFeeDiscount:      getEnvBool("BNB_FEE_DISCOUNT", false),
+
+		ExplainMode: getEnvBool("EXPLAIN", false),
+
+		BuyDropMin:          buyDropMin,
+		BuyDropMax:          buyDropMax,
+		SafetyDropLimit:     safetyDropLimit,
+		StopLossPercent:     stopLossPercent,
+		TrailingStopPercent: trailingStopPercent,
+		MaxHold:             time.Duration(maxHoldHours) * time.Hour,
+		MaxOpenPositions:    maxOpenPositions,
+		BuyConcurrency:      buyConcurrency,
+		RebuyCooldown:       time.Duration(rebuyCooldownMinutes) * time.Minute,
+		MaxTradeUSDT:        maxTradeUSDT,
+		MaxTotalExposure:    maxTotalExposure,
+		MaxDailyLoss:        maxDailyLoss,
+		MinNetProfit:        minNetProfit,
+		SellTranches:        sellTranches,
+
+		WeightLimit: weightLimit,
+
+		DryRun: dryRun,
+
+		SimulatedSlippage: simulatedSlippage,
+
+		RunOnce:     getEnvBool("RUN_ONCE", false),
+		AutoConfirm: getEnvBool("AUTO_CONFIRM", false),
+
+		MaintenanceCooldown: time.Duration(maintenanceCooldownMinutes) * time.Minute,
 	}
 
 	return bot, nil
 }
 
 func (bot *TradingBot) fetchTop20CoinsFromCMC() ([]OptimizedTicker, error) {
-	fmt.Println("Fetching top 20 non-stablecoin coins from CoinMarketCap API...")
+	logInfo.Printf("Fetching top %d non-stablecoin coins from CoinMarketCap API...\n", bot.WatchlistSize)
 
 	cmcAPIKey := os.Getenv("COIN_MARKET_CAP_API_KEY")
 	if cmcAPIKey == "" {
 		return nil, fmt.Errorf("COIN_MARKET_CAP_API_KEY not set in environment variables")
 	}
 
-	// Fetch top 50 to ensure we get 20 non-stablecoins after filtering
-	apiURL := "https://pro-api.coinmarketcap.com/v1/cryptocurrency/listings/latest?start=1&limit=50&convert=USD"
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating CMC request: %v", err)
+	// Once the configured fetch has left the watchlist short, later cycles fetch the expanded top directly
+	// instead of making two CMC calls every cycle
+	expandedLimit := min(2*bot.CMCFetchLimit, maxCMCFetchLimit)
+	limits := []int{bot.CMCFetchLimit, expandedLimit}
+	if bot.cmcExpanded {
+		limits = limits[1:]
 	}
 
-	req.Header.Set("X-CMC_PRO_API_KEY", cmcAPIKey)
-	req.Header.Set("Accept", "application/json")
+	var top20Coins []OptimizedTicker
+	var dropped cmcFilterCounts
+	filtered := 0
+	for _, limit := range limits {
+		cmcResponse, err := fetchCMCListings(bot.httpClient, cmcAPIKey, limit)
+		if err != nil {
+			return nil, err
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making CMC request: %v", err)
-	}
-	defer resp.Body.Close()
+		// The wider fetch repeats the listings already filtered, so only the new ones are checked
+		var newlyDropped cmcFilterCounts
+		top20Coins, newlyDropped = bot.filterCMCListings(cmcResponse.Data[min(filtered, len(cmcResponse.Data)):], top20Coins, limit)
+		dropped.add(newlyDropped)
+		filtered = len(cmcResponse.Data)
+		logInfo.Printf("Filters dropped %d CMC listings (top %d fetched): %d stablecoins, %d low volume, %d not tradeable on Binance\n",
+			dropped.total(), len(cmcResponse.Data), dropped.stablecoin, dropped.lowVolume, dropped.notOnBinance)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("CMC API request failed with status %d: %s", resp.StatusCode, string(body))
+		if len(top20Coins) >= bot.WatchlistSize || len(cmcResponse.Data) < limit || limit >= expandedLimit {
+			break
+		}
+		log.Printf("WARNING: Only %d of %d watchlist coins survived filtering the CMC top %d, expanding to the top %d from now on",
+			len(top20Coins), bot.WatchlistSize, limit, expandedLimit)
+		bot.cmcExpanded = true
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading CMC response: %v", err)
+	if len(top20Coins) < bot.WatchlistSize {
+		log.Printf("WARNING: Watchlist is under-populated: only %d of %d coins passed the filters", len(top20Coins), bot.WatchlistSize)
+		logEvent(slog.LevelWarn, "watchlist_short", "coins", len(top20Coins), "want", bot.WatchlistSize)
 	}
 
-	var cmcResponse CoinMarketCapResponse
-	err = json.Unmarshal(body, &cmcResponse)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing CMC JSON: %v", err)
-	}
+	bot.printWatchlistSummary(top20Coins, "CoinMarketCap API (no additional Binance API calls needed)")
 
-	if cmcResponse.Status.ErrorCode != 0 {
-		return nil, fmt.Errorf("CMC API error: %s", cmcResponse.Status.ErrorMessage)
-	}
+	return top20Coins, nil
+}
+
+// cmcFilterCounts tallies the CMC listings each watchlist filter dropped
+type cmcFilterCounts struct {
+	stablecoin   int
+	lowVolume    int
+	notOnBinance int
+}
+
+func (c cmcFilterCounts) total() int {
+	return c.stablecoin + c.lowVolume + c.notOnBinance
+}
+
+func (c *cmcFilterCounts) add(other cmcFilterCounts) {
+	c.stablecoin += other.stablecoin
+	c.lowVolume += other.lowVolume
+	c.notOnBinance += other.notOnBinance
+}
+
+// filterCMCListings adds listings that are not stablecoins, have enough volume and trade on Binance to the
+// watchlist until it holds WatchlistSize coins. Coins already on the watchlist are skipped, since CMC ranks
+// can shift between the fetches.
+func (bot *TradingBot) filterCMCListings(listings []CoinMarketCapCoin, watchlist []OptimizedTicker, limit int) ([]OptimizedTicker, cmcFilterCounts) {
+	var dropped cmcFilterCounts
+
+	// Only keep coins with a USDT pair that is actually trading on Binance
+	tradeable := bot.tradeableSymbols()
 
 	// Create OptimizedTicker array with non-stablecoin CMC top coins
-	top20Coins := make([]OptimizedTicker, 0, 20)
-	addedCount := 0
+	top20Coins := watchlist
+	if top20Coins == nil {
+		top20Coins = make([]OptimizedTicker, 0, bot.WatchlistSize)
+	}
+	listed := make(map[string]bool, len(top20Coins))
+	for _, coin := range top20Coins {
+		listed[coin.Symbol] = true
+	}
 
-	fmt.Println("\n=== FILTERING CMC TOP 50 FOR TRADING ===")
+	logInfo.Printf("\n=== FILTERING CMC TOP %d FOR TRADING ===\n", limit)
 
-	for _, coin := range cmcResponse.Data {
-		// Skip if already have 20 coins
-		if addedCount >= 20 {
-			break
+	for _, coin := range listings {
+		// Skip once the watchlist is full
+		if len(top20Coins) >= bot.WatchlistSize {
+			bot.explain(coin.Symbol, ReasonOutsideWatchlist, "watchlist already has %d coins", bot.WatchlistSize)
+			continue
 		}
 
 		symbol := coin.Symbol + "USDT"
+		if listed[symbol] {
+			continue
+		}
 		price := coin.Quote.USD.Price
 		change24h := coin.Quote.USD.PercentChange24h
 
+		// A rebound strategy needs coins that can actually drop, so never trade stablecoins
+		if stable, why := bot.isStablecoin(coin.Symbol, price, change24h); stable {
+			logInfo.Printf("SKIP: %s: stablecoin (%s)\n", coin.Symbol, why)
+			bot.explain(symbol, ReasonStablecoin, "%s", why)
+			dropped.stablecoin++
+			continue
+		}
+
+		if bot.lowVolume(symbol, coin.Quote.USD.Volume24h) {
+			dropped.lowVolume++
+			continue
+		}
+
+		if tradeable != nil && !tradeable[symbol] {
+			logInfo.Printf("SKIP: %s: no tradeable %s pair on Binance\n", coin.Symbol, symbol)
+			bot.explain(symbol, ReasonNotOnBinance, "no trading %s pair in exchangeInfo", symbol)
+			dropped.notOnBinance++
+			continue
+		}
+
 		// Use CoinMarketCap data directly - no need for additional Binance call
 		top20Coins = append(top20Coins, OptimizedTicker{
 			Symbol:             symbol,
 			LastPrice:          price,
 			PriceChangePercent: change24h,
+			Volume24h:          coin.Quote.USD.Volume24h,
 		})
 
-		// Enhanced logging for buy opportunities
-		buySignal := ""
-		if change24h <= -5.0 && change24h > -10.0 {
-			buySignal = " 🔥 BUY SIGNAL!"
-		} else if change24h <= -4.5 && change24h > -5.0 {
-			buySignal = " ⚡ WATCH (close to threshold)"
-		} else if change24h <= -10.0 {
-			buySignal = " ⚠️  DANGER ZONE (>10% drop)"
-		}
-
-		fmt.Printf("ADD: %s: $%.4f (%.2f%% 24h)%s\n",
-			coin.Symbol, price, change24h, buySignal)
-
-		addedCount++
-	}
-
-	fmt.Printf("\n=== SUMMARY ===\n")
-	fmt.Printf("Successfully loaded %d tradeable non-stablecoin coins\n", len(top20Coins))
-	fmt.Printf("Data source: CoinMarketCap API (no additional Binance API calls needed)\n")
-
-	// Show buy opportunities summary
-	buyOpportunities := 0
-	watchList := 0
-	for _, coin := range top20Coins {
-		if coin.PriceChangePercent <= -5.0 && coin.PriceChangePercent > -10.0 {
-			buyOpportunities++
-		} else if coin.PriceChangePercent <= -4.5 && coin.PriceChangePercent > -5.0 {
-			watchList++
-		}
-	}
-
-	if buyOpportunities > 0 {
-		fmt.Printf("IMMEDIATE BUY OPPORTUNITIES: %d coins (5-10%% drop range)\n", buyOpportunities)
-	}
-	if watchList > 0 {
-		fmt.Printf("⚡ WATCH LIST: %d coins (close to 5%% threshold)\n", watchList)
+		bot.logWatchlistCoin(coin.Symbol, price, change24h)
 	}
-	if buyOpportunities == 0 && watchList == 0 {
-		fmt.Printf("NO IMMEDIATE OPPORTUNITIES: Market is stable\n")
-	}
-
-	return top20Coins, nil
-}
 
-// generateSignature creates HMAC SHA256 signature for Binance API
-func (bot *TradingBot) generateSignature(queryString string) string {
-	mac := hmac.New(sha256.New, []byte(bot.BinanceConfig.SecretKey))
-	mac.Write([]byte(queryString))
-	return hex.EncodeToString(mac.Sum(nil))
+	return top20Coins, dropped
 }
 
 // SymbolFilters holds the trading rules for a specific symbol
 type SymbolFilters struct {
-	StepSize string `json:"stepSize"`
-	TickSize string `json:"tickSize"`
+	StepSize       string `json:"stepSize"`
+	TickSize       string `json:"tickSize"`
+	MinNotional    string `json:"minNotional"`
+	QuotePrecision int    `json:"quotePrecision"` // Decimal places allowed for quote amounts (quoteOrderQty)
 }
 
 // ExchangeInfo represents the Binance exchange info response for symbol filters
 type ExchangeInfo struct {
-	Symbols []struct {
-		Symbol  string `json:"symbol"`
-		Filters []struct {
-			FilterType string `json:"filterType"`
-			StepSize   string `json:"stepSize,omitempty"`
-			TickSize   string `json:"tickSize,omitempty"`
-		} `json:"filters"`
-	} `json:"symbols"`
+	Symbols []ExchangeSymbol `json:"symbols"`
 }
 
-// getSymbolFilters fetches trading rules for a specific symbol from Binance
-func (bot *TradingBot) getSymbolFilters(symbol string) (*SymbolFilters, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
-	apiURL := bot.BinanceConfig.BaseURL + "/api/v3/exchangeInfo?symbol=" + symbol
-
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating exchange info request: %v", err)
-	}
+// ExchangeSymbol is a single symbol's status and trading rules from exchange info
+type ExchangeSymbol struct {
+	Symbol              string `json:"symbol"`
+	Status              string `json:"status"`
+	QuoteAsset          string `json:"quoteAsset"`
+	QuotePrecision      int    `json:"quotePrecision"`
+	QuoteAssetPrecision int    `json:"quoteAssetPrecision"`
+	Filters             []struct {
+		FilterType  string `json:"filterType"`
+		StepSize    string `json:"stepSize,omitempty"`
+		TickSize    string `json:"tickSize,omitempty"`
+		MinNotional string `json:"minNotional,omitempty"`
+	} `json:"filters"`
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error getting exchange info: %v", err)
-	}
-	defer resp.Body.Close()
+// defaultExchangeInfoTTL is how long cached symbol filters and the tradeable-symbol set stay valid
+const defaultExchangeInfoTTL = time.Hour
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading exchange info response: %v", err)
-	}
+// cachedSymbolFilters is a symbol's filters along with when they were fetched
+type cachedSymbolFilters struct {
+	filters   *SymbolFilters
+	fetchedAt time.Time
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("exchange info request failed with status %d: %s", resp.StatusCode, string(body))
+// getSymbolFilters returns trading rules for a specific symbol, fetching from Binance on a cache miss
+func (bot *TradingBot) getSymbolFilters(symbol string) (*SymbolFilters, error) {
+	bot.exchangeInfoMu.Lock()
+	cached, ok := bot.symbolFilters[symbol]
+	bot.exchangeInfoMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < bot.ExchangeInfoTTL {
+		return cached.filters, nil
 	}
 
-	var exchangeInfo ExchangeInfo
-	err = json.Unmarshal(body, &exchangeInfo)
+	exchangeInfo, err := bot.Binance.ExchangeInfo(symbol)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing exchange info: %v", err)
+		return nil, err
 	}
 
 	if len(exchangeInfo.Symbols) == 0 {
 		return nil, fmt.Errorf("symbol %s not found", symbol)
 	}
 
-	symbolInfo := exchangeInfo.Symbols[0]
-	filters := &SymbolFilters{}
+	filters := parseSymbolFilters(exchangeInfo.Symbols[0])
+
+	bot.exchangeInfoMu.Lock()
+	if bot.symbolFilters == nil {
+		bot.symbolFilters = make(map[string]cachedSymbolFilters)
+	}
+	bot.symbolFilters[symbol] = cachedSymbolFilters{filters: filters, fetchedAt: time.Now()}
+	bot.exchangeInfoMu.Unlock()
+
+	return filters, nil
+}
+
+// parseSymbolFilters extracts the filters the bot uses from a symbol's exchange info
+func parseSymbolFilters(symbolInfo ExchangeSymbol) *SymbolFilters {
+	filters := &SymbolFilters{QuotePrecision: symbolInfo.QuoteAssetPrecision}
+	if filters.QuotePrecision == 0 {
+		filters.QuotePrecision = symbolInfo.QuotePrecision // Older field, same meaning
+	}
 
 	for _, filter := range symbolInfo.Filters {
 		switch filter.FilterType {
@@ -230,239 +701,459 @@ func (bot *TradingBot) getSymbolFilters(symbol string) (*SymbolFilters, error) {
 			filters.StepSize = filter.StepSize
 		case "PRICE_FILTER":
 			filters.TickSize = filter.TickSize
+		case "MIN_NOTIONAL", "NOTIONAL":
+			filters.MinNotional = filter.MinNotional
 		}
 	}
 
-	return filters, nil
+	return filters
 }
 
-// roundToTickSize rounds a price to the correct tick size for Binance
-func roundToTickSize(price float64, tickSize string) float64 {
-	tick, err := strconv.ParseFloat(tickSize, 64)
-	if err != nil || tick <= 0 {
-		return price
+// prefetchExchangeInfo loads exchange info for every symbol in one request, filling the filter cache
+// and the tradeable-symbol set. Does nothing while the previous snapshot is still within the TTL.
+func (bot *TradingBot) prefetchExchangeInfo() error {
+	bot.exchangeInfoMu.Lock()
+	fresh := bot.TradeableSymbols != nil && time.Since(bot.TradeableSymbolsAt) < bot.ExchangeInfoTTL
+	bot.exchangeInfoMu.Unlock()
+	if fresh {
+		return nil
 	}
 
-	// Round to the nearest tick
-	return float64(int64(price/tick+0.5)) * tick
-}
+	exchangeInfo, err := bot.Binance.ExchangeInfo("")
+	if err != nil {
+		return err
+	}
 
-// executeBuyOrder places a market buy order on Binance
-func (bot *TradingBot) executeBuyOrder(symbol string, quoteOrderQty float64) (*OrderResponse, error) {
-	if bot.BinanceConfig.APIKey == "" || bot.BinanceConfig.SecretKey == "" {
-		return nil, fmt.Errorf("Binance API credentials not configured")
+	now := time.Now()
+	symbols := make(map[string]bool)
+	filters := make(map[string]cachedSymbolFilters, len(exchangeInfo.Symbols))
+	for _, symbolInfo := range exchangeInfo.Symbols {
+		filters[symbolInfo.Symbol] = cachedSymbolFilters{filters: parseSymbolFilters(symbolInfo), fetchedAt: now}
+		if symbolInfo.Status == "TRADING" && symbolInfo.QuoteAsset == "USDT" {
+			symbols[symbolInfo.Symbol] = true
+		}
 	}
 
-	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	bot.exchangeInfoMu.Lock()
+	bot.symbolFilters = filters
+	bot.TradeableSymbols = symbols
+	bot.TradeableSymbolsAt = now
+	bot.exchangeInfoMu.Unlock()
 
-	//order parameters
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("side", "BUY")
-	params.Set("type", "MARKET")
-	params.Set("quoteOrderQty", fmt.Sprintf("%.8f", quoteOrderQty))
-	params.Set("timestamp", fmt.Sprintf("%d", timestamp))
+	logInfo.Printf("Cached exchange info for %d symbols (%d tradeable USDT pairs)\n", len(filters), len(symbols))
+	return nil
+}
 
-	queryString := params.Encode()
-	signature := bot.generateSignature(queryString)
+// tradeableSymbols returns the set of USDT pairs currently trading on Binance from the exchange info cache.
+// Returns nil if exchange info cannot be fetched and nothing is cached, so callers can skip the check.
+func (bot *TradingBot) tradeableSymbols() map[string]bool {
+	if err := bot.prefetchExchangeInfo(); err != nil {
+		log.Printf("WARNING: Could not load Binance symbols, using cached listing check: %v", err)
+	}
 
-	// Create request
-	orderURL := bot.BinanceConfig.BaseURL + "/api/v3/order"
-	req, err := http.NewRequest("POST", orderURL, strings.NewReader(queryString+"&signature="+signature))
-	if err != nil {
-		return nil, fmt.Errorf("error creating buy order request: %v", err)
+	// Stale data beats none
+	bot.exchangeInfoMu.Lock()
+	defer bot.exchangeInfoMu.Unlock()
+	return bot.TradeableSymbols
+}
+
+// getTickerPrice fetches the latest price for a symbol from Binance
+func (bot *TradingBot) getTickerPrice(symbol string) (float64, error) {
+	return bot.Binance.TickerPrice(symbol)
+}
+
+// validateStartupBudget fails fast if the budget or investment amount cannot place a valid order
+func (bot *TradingBot) validateStartupBudget() error {
+	amount := bot.tradeAmount(0)
+	if amount <= 0 || bot.AvailableBudget < amount {
+		return fmt.Errorf("available budget %.2f USDT is below the investment amount %.2f USDT - cannot fund a single trade",
+			bot.AvailableBudget, amount)
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("X-MBX-APIKEY", bot.BinanceConfig.APIKey)
+	logInfo.Println("\nValidating investment amount against Binance minimum notional...")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	candidates, err := bot.fetchWatchList()
 	if err != nil {
-		return nil, fmt.Errorf("error executing buy order: %v", err)
+		return fmt.Errorf("could not fetch candidate symbols: %v", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading buy order response: %v", err)
+	maxMinNotional := 0.0
+	maxSymbol := ""
+	for _, coin := range candidates {
+		filters, err := bot.getSymbolFilters(coin.Symbol)
+		if err != nil {
+			logWarn.Printf("WARNING: %s: could not get symbol filters: %v\n", coin.Symbol, err)
+			continue
+		}
+
+		minNotional, err := strconv.ParseFloat(filters.MinNotional, 64)
+		if err != nil {
+			continue
+		}
+
+		if minNotional > amount {
+			logWarn.Printf("WARNING: %s requires at least %.2f USDT per order (investment amount %.2f USDT)\n",
+				coin.Symbol, minNotional, amount)
+		}
+		if minNotional > maxMinNotional {
+			maxMinNotional = minNotional
+			maxSymbol = coin.Symbol
+		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("buy order failed with status %d: %s", resp.StatusCode, string(body))
+	// Percent sizing raises small trades to the symbol's minimum, so only the budget itself has to clear it
+	if bot.InvestmentMode == investmentModePercent {
+		if bot.AvailableBudget < maxMinNotional {
+			return fmt.Errorf("available budget %.2f USDT is below the minimum notional %.2f USDT required by %s",
+				bot.AvailableBudget, maxMinNotional, maxSymbol)
+		}
+		logInfo.Printf("SUCCESS: Investing %.1f%% of available budget (%.2f USDT now), raised to each symbol's minimum notional when needed\n",
+			bot.InvestmentPercent, amount)
+		return nil
 	}
 
-	var orderResp OrderResponse
-	err = json.Unmarshal(body, &orderResp)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing buy order response: %v", err)
+	if amount < maxMinNotional {
+		return fmt.Errorf("investment amount %.2f USDT is below the minimum notional %.2f USDT required by %s",
+			amount, maxMinNotional, maxSymbol)
 	}
 
-	return &orderResp, nil
+	logInfo.Printf("SUCCESS: Investment amount %.2f USDT clears the highest minimum notional (%.2f USDT)\n",
+		amount, maxMinNotional)
+	return nil
 }
 
-// executeLimitSellOrder places a limit sell order on Binance
-func (bot *TradingBot) executeLimitSellOrder(symbol string, quantity float64, price float64) (*OrderResponse, error) {
-	if bot.BinanceConfig.APIKey == "" || bot.BinanceConfig.SecretKey == "" {
-		return nil, fmt.Errorf("Binance API credentials not configured")
-	}
-
-	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+// truncateToPrecision truncates a value to the given number of decimal places without rounding up
+func truncateToPrecision(value float64, precision int) float64 {
+	factor := math.Pow(10, float64(precision))
+	// Small epsilon so values like 0.29 (stored as 0.2899999...) don't lose a digit
+	return math.Floor(value*factor+1e-9) / factor
+}
 
-	// Prepare order parameters
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("side", "SELL")
-	params.Set("type", "LIMIT")
-	params.Set("timeInForce", "GTC") // Good Till Cancelled
-	params.Set("quantity", fmt.Sprintf("%.8f", quantity))
-	params.Set("price", fmt.Sprintf("%.8f", price))
-	params.Set("timestamp", fmt.Sprintf("%d", timestamp))
+// RoundingMode selects which way a price is rounded to the tick size
+type RoundingMode int
 
-	queryString := params.Encode()
-	signature := bot.generateSignature(queryString)
+const (
+	RoundNearest RoundingMode = iota // Half up to the nearest tick
+	RoundUp                          // Up to the next tick, so a sell target keeps its full profit
+	RoundDown                        // Down to the previous tick, so a buy limit never pays more than intended
+)
 
-	// Create request
-	orderURL := bot.BinanceConfig.BaseURL + "/api/v3/order"
-	req, err := http.NewRequest("POST", orderURL, strings.NewReader(queryString+"&signature="+signature))
-	if err != nil {
-		return nil, fmt.Errorf("error creating limit sell order request: %v", err)
+// parseRoundingMode parses a rounding mode setting (up, down or nearest), reporting false for anything else
+func parseRoundingMode(raw string) (RoundingMode, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "up", "ceil":
+		return RoundUp, true
+	case "down", "floor":
+		return RoundDown, true
+	case "nearest":
+		return RoundNearest, true
+	default:
+		return RoundNearest, false
 	}
+}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("X-MBX-APIKEY", bot.BinanceConfig.APIKey)
+// roundToTickSize rounds a price to the correct tick size for Binance in the given direction
+func roundToTickSize(price float64, tickSize string, mode RoundingMode) float64 {
+	tick, err := strconv.ParseFloat(tickSize, 64)
+	if err != nil || tick <= 0 {
+		return price
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error executing limit sell order: %v", err)
+	// The epsilon keeps prices already on a tick (or like 1.005, stored as 1.00499999...) from being pushed
+	// across the boundary by float noise, and the result is trimmed of float noise like roundToStepSize
+	var ticks float64
+	switch mode {
+	case RoundUp:
+		ticks = math.Ceil(price/tick - 1e-9)
+	case RoundDown:
+		ticks = math.Floor(price/tick + 1e-9)
+	default:
+		ticks = math.Floor(price/tick + 0.5 + 1e-9)
 	}
-	defer resp.Body.Close()
+	factor := math.Pow(10, float64(sizeDecimals(tickSize)))
+	return math.Round(ticks*tick*factor) / factor
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading limit sell order response: %v", err)
+// sizeDecimals returns the significant decimal places of a filter size such as "0.00100000" (3)
+func sizeDecimals(size string) int {
+	dot := strings.IndexByte(size, '.')
+	if dot < 0 {
+		return 0
 	}
+	return len(strings.TrimRight(size[dot+1:], "0"))
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("limit sell order failed with status %d: %s", resp.StatusCode, string(body))
+// orderPrecision derives the decimal places for order quantities and prices from the LOT_SIZE step size and
+// PRICE_FILTER tick size, keeping the default for a size that is missing or invalid
+func (f *SymbolFilters) orderPrecision() OrderPrecision {
+	prec := defaultOrderPrecision
+	if step, err := strconv.ParseFloat(f.StepSize, 64); err == nil && step > 0 {
+		prec.Quantity = sizeDecimals(f.StepSize)
 	}
+	if tick, err := strconv.ParseFloat(f.TickSize, 64); err == nil && tick > 0 {
+		prec.Price = sizeDecimals(f.TickSize)
+	}
+	return prec
+}
 
-	var orderResp OrderResponse
-	err = json.Unmarshal(body, &orderResp)
+// orderPrecision returns the order precision for a symbol, falling back to 8 decimals if filters are unavailable
+func (bot *TradingBot) orderPrecision(symbol string) OrderPrecision {
+	filters, err := bot.getSymbolFilters(symbol)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing limit sell order response: %v", err)
+		logWarn.Printf("   WARNING: Could not get symbol filters for %s, formatting order with 8 decimals: %v\n", symbol, err)
+		return defaultOrderPrecision
 	}
-
-	return &orderResp, nil
+	return filters.orderPrecision()
 }
 
-// executeSellOrder places a market sell order on Binance
-func (bot *TradingBot) executeSellOrder(symbol string, quantity float64) (*OrderResponse, error) {
-	if bot.BinanceConfig.APIKey == "" || bot.BinanceConfig.SecretKey == "" {
-		return nil, fmt.Errorf("Binance API credentials not configured")
+// targetAboveBuyPrice checks that a tick-rounded target sell price is at least one tick above the buy price,
+// raising it by one tick when it isn't. It reports whether the price was raised.
+func targetAboveBuyPrice(target, buyPrice float64, tickSize string) (float64, bool) {
+	tick, err := strconv.ParseFloat(tickSize, 64)
+	if err != nil || tick <= 0 {
+		return target, false
 	}
 
-	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
-
-	// Prepare order parameters
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("side", "SELL")
-	params.Set("type", "MARKET")
-	params.Set("quantity", fmt.Sprintf("%.8f", quantity))
-	params.Set("timestamp", fmt.Sprintf("%d", timestamp))
-
-	queryString := params.Encode()
-	signature := bot.generateSignature(queryString)
+	// Allow for float noise in the rounded price
+	if target-buyPrice >= tick*(1-1e-9) {
+		return target, false
+	}
+	return roundToTickSize(target+tick, tickSize, RoundNearest), true
+}
 
-	// Create request
-	orderURL := bot.BinanceConfig.BaseURL + "/api/v3/order"
-	req, err := http.NewRequest("POST", orderURL, strings.NewReader(queryString+"&signature="+signature))
-	if err != nil {
-		return nil, fmt.Errorf("error creating sell order request: %v", err)
+// roundToStepSize rounds a quantity down to the LOT_SIZE step size so a sell never exceeds the held amount
+func roundToStepSize(quantity float64, stepSize string) float64 {
+	step, err := strconv.ParseFloat(stepSize, 64)
+	if err != nil || step <= 0 {
+		return quantity
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("X-MBX-APIKEY", bot.BinanceConfig.APIKey)
+	// Floor to whole steps, then trim float noise to the step's decimal places (e.g. 1.2340000000000002)
+	steps := math.Floor(quantity/step + 1e-9)
+	factor := math.Pow(10, float64(sizeDecimals(stepSize)))
+	return math.Round(steps*step*factor) / factor
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// sellQuantity rounds a position quantity to the symbol's step size, leaving it unchanged if filters are unavailable
+func (bot *TradingBot) sellQuantity(symbol string, quantity float64) float64 {
+	filters, err := bot.getSymbolFilters(symbol)
 	if err != nil {
-		return nil, fmt.Errorf("error executing sell order: %v", err)
+		logWarn.Printf("   WARNING: Could not get symbol filters for %s, selling unrounded quantity: %v\n", symbol, err)
+		return quantity
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading sell order response: %v", err)
+	rounded := roundToStepSize(quantity, filters.StepSize)
+	if rounded != quantity {
+		logInfo.Printf("   [QUANTITY ADJUSTMENT] Original: %.8f -> Rounded: %.8f (StepSize: %s)\n",
+			quantity, rounded, filters.StepSize)
 	}
+	return rounded
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("sell order failed with status %d: %s", resp.StatusCode, string(body))
+// executeBuyOrder places a market buy order on Binance
+func (bot *TradingBot) executeBuyOrder(symbol string, quoteOrderQty float64) (*OrderResponse, error) {
+	if bot.DryRun {
+		return bot.simulateBuyOrder(symbol, quoteOrderQty)
 	}
 
-	var orderResp OrderResponse
-	err = json.Unmarshal(body, &orderResp)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing sell order response: %v", err)
+	// Truncate the quote amount to the precision Binance allows for this symbol
+	quotePrecision := 8
+	if filters, err := bot.getSymbolFilters(symbol); err == nil && filters.QuotePrecision > 0 {
+		quotePrecision = filters.QuotePrecision
 	}
+	quoteOrderQty = truncateToPrecision(quoteOrderQty, quotePrecision)
 
-	return &orderResp, nil
+	orderResp, err := bot.Binance.Buy(symbol, quoteOrderQty, quotePrecision)
+	return orderResp, bot.watchMaintenance(err)
 }
 
-// getRealUSDTBalance fetches the actual USDT balance from Binance for budget initialization
-func getRealUSDTBalance(apiKey, secretKey string) (float64, error) {
-	if apiKey == "" || secretKey == "" {
-		return 0, fmt.Errorf("Binance API credentials not configured")
+// executeLimitBuyOrder buys with a GTC limit order priced BuyLimitSlippage above the current price, so a
+// volatile market can't fill it at a worse price. Whatever hasn't filled after BuyLimitTimeout is cancelled.
+func (bot *TradingBot) executeLimitBuyOrder(symbol string, quoteAmount float64, lastPrice float64) (*OrderResponse, error) {
+	// The watchlist price can be minutes old, so price the order off the live ticker when possible
+	price := lastPrice
+	if tickerPrice, err := bot.getTickerPrice(symbol); err == nil {
+		price = tickerPrice
 	}
 
-	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
-
-	params := url.Values{}
-	params.Set("timestamp", fmt.Sprintf("%d", timestamp))
-
-	queryString := params.Encode()
-
-	// Generate signature
-	mac := hmac.New(sha256.New, []byte(secretKey))
-	mac.Write([]byte(queryString))
-	signature := hex.EncodeToString(mac.Sum(nil))
-
-	accountURL := "https://api.binance.com/api/v3/account?" + queryString + "&signature=" + signature
-
-	req, err := http.NewRequest("GET", accountURL, nil)
+	filters, err := bot.getSymbolFilters(symbol)
 	if err != nil {
-		return 0, fmt.Errorf("error creating account info request: %v", err)
+		return nil, fmt.Errorf("error getting symbol filters for limit buy: %v", err)
+	}
+	limitPrice := roundToTickSize(price*(1+bot.BuyLimitSlippage/100), filters.TickSize, RoundDown)
+	quantity := roundToStepSize(quoteAmount/limitPrice, filters.StepSize)
+	if limitPrice <= 0 || quantity <= 0 {
+		return nil, fmt.Errorf("limit buy of %.2f USDT at $%.8f rounds to nothing", quoteAmount, limitPrice)
 	}
 
-	req.Header.Set("X-MBX-APIKEY", apiKey)
+	if bot.DryRun {
+		return bot.simulateLimitBuyOrder(symbol, quantity, limitPrice)
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	orderResp, err := bot.Binance.BuyLimit(symbol, quantity, limitPrice, filters.orderPrecision())
 	if err != nil {
-		return 0, fmt.Errorf("error getting account info: %v", err)
+		return nil, bot.watchMaintenance(err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading account response: %v", err)
+	logInfo.Printf("   Limit buy %d: %.8f %s at $%.8f (%.2f%% above $%.8f), waiting up to %s to fill\n",
+		orderResp.OrderID, quantity, symbol, limitPrice, bot.BuyLimitSlippage, price, bot.BuyLimitTimeout)
+
+	if orderResp.Status == StatusFilled {
+		return orderResp, nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("account info request failed with status %d: %s", resp.StatusCode, string(body))
+	deadline := time.Now().Add(bot.BuyLimitTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(min(time.Second, time.Until(deadline)))
+
+		order, err := bot.queryOrder(symbol, orderResp.OrderID)
+		if err != nil {
+			logWarn.Printf("   WARNING: Could not check limit buy %d: %v\n", orderResp.OrderID, err)
+			continue
+		}
+		if order.Status == StatusFilled {
+			return bot.withEstimatedBuyFills(order, orderResp.Fills), nil
+		}
 	}
 
-	var accountInfo AccountInfo
-	err = json.Unmarshal(body, &accountInfo)
-	if err != nil {
-		return 0, fmt.Errorf("error parsing account response: %v", err)
+	// Cancel the rest; the cancel response reports what filled in the meantime
+	final := bot.cancelLimitBuy(symbol, orderResp.OrderID)
+	if executedQty, _ := strconv.ParseFloat(final.ExecutedQty, 64); executedQty <= 0 {
+		return nil, fmt.Errorf("limit buy %d at $%.8f not filled within %s, %s",
+			orderResp.OrderID, limitPrice, bot.BuyLimitTimeout, strings.ToLower(string(final.Status)))
 	}
 
-	// Find USDT balance
-	for _, balance := range accountInfo.Balances {
-		if balance.Asset == "USDT" {
+	return bot.withEstimatedBuyFills(final, orderResp.Fills), nil
+}
+
+// cancelLimitBuy cancels a limit buy that timed out and returns the finished order. A buy left resting would
+// keep spending USDT untracked, so it retries with backoff until the cancel succeeds or Binance reports the
+// order filled, cancelled or expired.
+func (bot *TradingBot) cancelLimitBuy(symbol string, orderID int64) *OrderResponse {
+	for attempt := 1; ; attempt++ {
+		bot.throttleRequestWeight()
+		cancelled, err := bot.cancelOrder(symbol, orderID)
+		if err == nil {
+			return cancelled
+		}
+
+		// The cancel fails with unknown order when the buy finished just before it
+		order, queryErr := bot.queryOrder(symbol, orderID)
+		if queryErr == nil {
+			switch order.Status {
+			case StatusFilled, StatusCanceled, StatusExpired, StatusRejected:
+				return order
+			}
+		}
+
+		delay := max(min(backoffDelay(bot.SellRetryBackoff, min(attempt, 6)), time.Minute), time.Second)
+		if binanceErrorCode(err) == binanceCodeTooManyRequests {
+			delay = max(delay, weightResetDelay())
+		}
+		logWarn.Printf("   WARNING: Could not cancel limit buy %d (%v), retrying in %s\n", orderID, err, delay)
+		time.Sleep(delay)
+	}
+}
+
+// withEstimatedBuyFills attaches fills to a buy order that filled after it was placed. Order queries
+// and cancels don't report fills, so the part filled later is booked at the average price with an
+// estimated taker commission in the base asset (or BNB with the fee discount).
+func (bot *TradingBot) withEstimatedBuyFills(order *OrderResponse, initialFills []Fill) *OrderResponse {
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQty, 64)
+	quoteQty, _ := strconv.ParseFloat(order.QuoteQty, 64)
+
+	fills := append([]Fill(nil), initialFills...)
+	filledQty, filledQuote := 0.0, 0.0
+	for _, fill := range initialFills {
+		qty, _ := strconv.ParseFloat(fill.Qty, 64)
+		price, _ := strconv.ParseFloat(fill.Price, 64)
+		filledQty += qty
+		filledQuote += qty * price
+	}
+
+	if laterQty := executedQty - filledQty; laterQty > 0 {
+		price := (quoteQty - filledQuote) / laterQty
+		commission := laterQty * bot.TakerFeePercent / 100
+		commissionAsset := strings.TrimSuffix(order.Symbol, "USDT")
+		if bot.BNBFeeDiscount {
+			if bnbPrice, err := bot.bnbPrice(); err == nil && bnbPrice > 0 {
+				commission = laterQty * price * bot.buyFeePercent() / 100 / bnbPrice
+				commissionAsset = "BNB"
+			}
+		}
+		fills = append(fills, Fill{
+			Price:           strconv.FormatFloat(price, 'f', -1, 64),
+			Qty:             strconv.FormatFloat(laterQty, 'f', -1, 64),
+			Commission:      strconv.FormatFloat(commission, 'f', -1, 64),
+			CommissionAsset: commissionAsset,
+		})
+	}
+
+	order.Fills = fills
+	return order
+}
+
+// executeLimitSellOrder places a limit sell order on Binance
+func (bot *TradingBot) executeLimitSellOrder(symbol string, quantity float64, price float64) (*OrderResponse, error) {
+	if bot.DryRun {
+		return bot.simulateLimitSellOrder(symbol, quantity, price)
+	}
+
+	orderResp, err := bot.Binance.SellLimit(symbol, quantity, price, bot.orderPrecision(symbol))
+	return orderResp, bot.watchMaintenance(err)
+}
+
+// executeOCOSellOrder places an OCO sell on Binance: a LIMIT_MAKER at the target price and a STOP_LOSS_LIMIT
+// triggered at stopPrice. Whichever leg fills first cancels the other.
+func (bot *TradingBot) executeOCOSellOrder(symbol string, quantity, price, stopPrice, stopLimitPrice float64) (*OCOResponse, error) {
+	if bot.DryRun {
+		return bot.simulateOCOSellOrder(symbol, quantity, price, stopPrice, stopLimitPrice)
+	}
+
+	ocoResp, err := bot.Binance.SellOCO(symbol, quantity, price, stopPrice, stopLimitPrice, bot.orderPrecision(symbol))
+	return ocoResp, bot.watchMaintenance(err)
+}
+
+// executeSellOrder places a market sell order on Binance
+func (bot *TradingBot) executeSellOrder(symbol string, quantity float64) (*OrderResponse, error) {
+	if bot.DryRun {
+		return bot.simulateSellOrder(symbol, quantity)
+	}
+
+	orderResp, err := bot.Binance.SellMarket(symbol, quantity, bot.orderPrecision(symbol))
+	return orderResp, bot.watchMaintenance(err)
+}
+
+// queryOrder fetches the current state of an order from Binance
+func (bot *TradingBot) queryOrder(symbol string, orderID int64) (*OrderResponse, error) {
+	if bot.DryRun {
+		return bot.simulateQueryOrder(symbol, orderID)
+	}
+
+	order, err := bot.Binance.QueryOrder(symbol, orderID)
+	return order, bot.watchMaintenance(err)
+}
+
+// cancelOrder cancels an open order on Binance and returns the cancelled order
+func (bot *TradingBot) cancelOrder(symbol string, orderID int64) (*OrderResponse, error) {
+	if bot.DryRun {
+		return bot.simulateCancelOrder(orderID)
+	}
+
+	order, err := bot.Binance.CancelOrder(symbol, orderID)
+	return order, bot.watchMaintenance(err)
+}
+
+// getRealUSDTBalance fetches the actual USDT balance from Binance for budget initialization
+func getRealUSDTBalance(binance *BinanceClient) (float64, error) {
+	accountInfo, err := binance.AccountInfo()
+	if err != nil {
+		return 0, err
+	}
+
+	// Find USDT balance
+	for _, balance := range accountInfo.Balances {
+		if balance.Asset == "USDT" {
 			usdtBalance, err := strconv.ParseFloat(balance.Free, 64)
 			if err != nil {
 				return 0, fmt.Errorf("error parsing USDT balance: %v", err)
@@ -474,293 +1165,2162 @@ func getRealUSDTBalance(apiKey, secretKey string) (float64, error) {
 	return 0, fmt.Errorf("USDT balance not found in account")
 }
 
+// pingBinanceAuth makes a signed account request to confirm the API keys work and can trade,
+// turning Binance's auth error codes into an actionable message
+func pingBinanceAuth(binance *BinanceClient) error {
+	accountInfo, err := binance.AccountInfo()
+	if err != nil {
+		switch binanceErrorCode(err) {
+		case binanceCodeBadAPIKeyFormat:
+			return fmt.Errorf("Binance rejected the API key format - check BINANCE_API_KEY was copied completely: %v", err)
+		case binanceCodeRejectedMbxKey:
+			return fmt.Errorf("Binance rejected the API key - check it is correct, not deleted, has spot trading enabled "+
+				"and (if IP-restricted) allows this machine's IP. Testnet keys need BINANCE_BASE_URL=https://testnet.binance.vision: %v", err)
+		case binanceCodeInvalidSignature:
+			return fmt.Errorf("Binance rejected the request signature - check BINANCE_SECRET_KEY matches the API key: %v", err)
+		case binanceCodeTimestamp:
+			return fmt.Errorf("request timestamp outside recvWindow - sync the system clock or raise RECV_WINDOW_MS: %v", err)
+		}
+		return fmt.Errorf("authenticated ping to Binance failed: %v", err)
+	}
+
+	if !accountInfo.CanTrade {
+		return fmt.Errorf("API keys work but the account cannot trade - enable spot trading for this key in Binance API management")
+	}
+
+	return nil
+}
+
+// watchThreshold is the drop at which a coin is flagged as approaching the buy band
+func (bot *TradingBot) watchThreshold() float64 {
+	return bot.BuyDropMin + 0.5
+}
+
+// Signal classifies a coin's 24h change against the buy band and safety limit
+type Signal int
+
+const (
+	SignalHold   Signal = iota // Not down enough to consider
+	SignalWatch                // Within 0.5% of the buy band
+	SignalBuy                  // Inside the buy band
+	SignalRisky                // Past the buy band but short of the safety limit
+	SignalDanger               // At or past the safety limit, never bought
+)
+
+func (s Signal) String() string {
+	switch s {
+	case SignalWatch:
+		return "WATCH"
+	case SignalBuy:
+		return "BUY"
+	case SignalRisky:
+		return "RISKY"
+	case SignalDanger:
+		return "DANGER"
+	default:
+		return "HOLD"
+	}
+}
+
+// classifyDropSignal classifies a 24h change in percent using the configured drop thresholds. It is the single
+// source of the signal boundaries for the watchlist, the analysis and the test-cmc command.
+func (bot *TradingBot) classifyDropSignal(pct float64) Signal {
+	switch {
+	case pct <= bot.SafetyDropLimit:
+		return SignalDanger
+	case pct <= bot.BuyDropMax:
+		return SignalRisky
+	case pct <= bot.BuyDropMin:
+		return SignalBuy
+	case pct <= bot.watchThreshold():
+		return SignalWatch
+	default:
+		return SignalHold
+	}
+}
+
+// dropBandLabel formats the buy band for log messages, e.g. "5-10%"
+func (bot *TradingBot) dropBandLabel() string {
+	return fmt.Sprintf("%g-%g%%", -bot.BuyDropMin, -bot.BuyDropMax)
+}
+
 // analyzeTradingOpportunities checks for buy opportunities based on the optimized strategy
-// Focuses specifically on 5-10% drops from CoinMarketCap top 20 (excluding stablecoins)
-func (bot *TradingBot) analyzeTradingOpportunities() {
-	fmt.Println("\n=== Analyzing Trading Opportunities (5-10% Drop Strategy) ===")
+// Focuses specifically on drops within the configured buy band (default 5-10%) from CoinMarketCap top 20.
+// It returns the number of buy signals found and the number of positions actually bought.
+func (bot *TradingBot) analyzeTradingOpportunities() (opportunities, bought int) {
+	logInfo.Printf("\n=== Analyzing Trading Opportunities (%s Drop Strategy) ===\n", bot.dropBandLabel())
 
 	buyOpportunities := 0
 	watchOpportunities := 0
+	lastSold := bot.lastSellTimes()
+	candidates := make([]OptimizedTicker, 0)
 
 	for _, coin := range bot.WatchList {
 		coinName := strings.TrimSuffix(coin.Symbol, "USDT")
 
-		// Safety check: Do not buy if price drops more than 11% (potential hack/major issue)
-		if coin.PriceChangePercent <= -11.0 {
-			fmt.Printf("SKIP %s: %.2f%% drop exceeds safety limit (-11%%)\n",
-				coinName, coin.PriceChangePercent)
+		// Coins held manually or never wanted stay out, whatever their drop
+		if bot.SymbolBlacklist[coinName] {
+			logInfo.Printf("SKIP %s: blacklisted (SYMBOL_BLACKLIST)\n", coinName)
+			bot.explain(coin.Symbol, ReasonBlacklisted, "in SYMBOL_BLACKLIST")
+			continue
+		}
+		if len(bot.SymbolWhitelist) > 0 && !bot.SymbolWhitelist[coinName] {
+			logInfo.Printf("SKIP %s: not whitelisted (SYMBOL_WHITELIST)\n", coinName)
+			bot.explain(coin.Symbol, ReasonNotWhitelisted, "not in SYMBOL_WHITELIST")
 			continue
 		}
 
-		// Watch for potential buy opportunities (close to threshold)
-		if coin.PriceChangePercent <= -4.5 && coin.PriceChangePercent > -5.0 {
-			fmt.Printf("👀 WATCH: %s at %.2f%% (approaching -5%% buy threshold)\n",
-				coinName, coin.PriceChangePercent)
-			watchOpportunities++
+		// A coin just sold at target is often still in the buy band, so give it time before rebuying
+		if soldAt, ok := lastSold[coin.Symbol]; ok && bot.RebuyCooldown > 0 {
+			if remaining := bot.RebuyCooldown - time.Since(soldAt); remaining > 0 {
+				logInfo.Printf("COOLDOWN %s: sold %s ago, can rebuy in %s\n",
+					coinName, formatAge(time.Since(soldAt)), formatAge(remaining))
+				bot.explain(coin.Symbol, ReasonRebuyCooldown, "sold %s ago, %s remaining",
+					formatAge(time.Since(soldAt)), formatAge(remaining))
+				continue
+			}
 		}
 
-		// Main buy condition: exactly what you specified - between 5% and 10% drop
-		if coin.PriceChangePercent <= -5.0 && coin.PriceChangePercent > -10.0 {
+		switch bot.classifyDropSignal(coin.PriceChangePercent) {
+		case SignalDanger:
+			// Safety check: Do not buy if price drops past the safety limit (potential hack/major issue)
+			logInfo.Printf("SKIP %s: %.2f%% drop exceeds safety limit (%.1f%%)\n",
+				coinName, coin.PriceChangePercent, bot.SafetyDropLimit)
+			bot.explain(coin.Symbol, ReasonSafetyLimit, "%.2f%% <= %.1f%%", coin.PriceChangePercent, bot.SafetyDropLimit)
+		case SignalBuy:
+			// Main buy condition: drop within the configured buy band
 			buyOpportunities++
-			fmt.Printf("BUY SIGNAL: %s dropped %.2f%% (perfect 5-10%% range)\n",
-				coinName, coin.PriceChangePercent)
-
-			// Execute real trade on Binance - this is where we actually use Binance API
-			fmt.Printf("Executing REAL trade: %.2f USDT of %s at $%.4f\n",
-				bot.InvestmentAmount, coinName, coin.LastPrice)
-			// if buyOpportunities == 1 {
-			bot.executeBuy(coin, coin.PriceChangePercent)
-			//}
-		} else if coin.PriceChangePercent > -5.0 {
+			logInfo.Printf("BUY SIGNAL: %s dropped %.2f%% (perfect %s range)\n",
+				coinName, coin.PriceChangePercent, bot.dropBandLabel())
+
+			// Safety check: a volume spike alongside the drop can signal a genuine crisis event
+			if ratio, abnormal := bot.checkVolumeSpike(coin); abnormal {
+				logWarn.Printf("ALERT: abnormal volume, skipping %s (%.1fx its recent average)\n", coinName, ratio)
+				bot.explain(coin.Symbol, ReasonAbnormalVolume, "volume %.1fx recent average (limit %.1fx)",
+					ratio, bot.VolumeSpikeMultiplier)
+				continue
+			}
+
+			// Buys run after the scan so they can be placed concurrently
+			candidates = append(candidates, coin)
+		case SignalWatch:
+			// Watch for potential buy opportunities (close to threshold)
+			logDebug.Printf("%s %s at %.2f%% (approaching %.1f%% buy threshold)\n",
+				emoji("\U0001F440 WATCH:", "[WATCH]"), coinName, coin.PriceChangePercent, bot.BuyDropMin)
+			watchOpportunities++
+			fallthrough
+		case SignalHold:
 			// Not enough drop yet
-			fmt.Printf("HOLD: %s at %.2f%% (need >5%% drop to trigger)\n",
-				coinName, coin.PriceChangePercent)
-		} else if coin.PriceChangePercent <= -10.0 && coin.PriceChangePercent > -11.0 {
+			logDebug.Printf("HOLD: %s at %.2f%% (need %.1f%% drop to trigger)\n",
+				coinName, coin.PriceChangePercent, bot.BuyDropMin)
+			bot.explain(coin.Symbol, ReasonDropTooSmall, "%.2f%% > %.1f%%", coin.PriceChangePercent, bot.BuyDropMin)
+		case SignalRisky:
 			// Too much drop - risky
-			fmt.Printf("RISKY: %s at %.2f%% (>10%% drop, potential issues)\n",
-				coinName, coin.PriceChangePercent)
+			logInfo.Printf("RISKY: %s at %.2f%% (past %.1f%% drop, potential issues)\n",
+				coinName, coin.PriceChangePercent, bot.BuyDropMax)
+			bot.explain(coin.Symbol, ReasonDropTooDeep, "%.2f%% <= %.1f%%", coin.PriceChangePercent, bot.BuyDropMax)
 		}
 	}
 
-	fmt.Printf("\n=== OPPORTUNITY SUMMARY ===\n")
+	if len(candidates) > 0 && bot.dailyLossHalted() {
+		for _, coin := range candidates {
+			bot.explain(coin.Symbol, ReasonDailyLossLimit, "%.2f%% drop, buying halted until midnight", coin.PriceChangePercent)
+		}
+		candidates = nil
+	}
+	nextID := bot.nextPositionID()
+	bot.executeBuys(candidates)
+	bought = bot.nextPositionID() - nextID
+
+	logInfo.Printf("\n=== OPPORTUNITY SUMMARY ===\n")
 	if buyOpportunities == 0 {
-		fmt.Println("No coins in the 5-10% drop range for buying")
+		logInfo.Printf("No coins in the %s drop range for buying\n", bot.dropBandLabel())
 		if watchOpportunities > 0 {
-			fmt.Printf("%d coins are close to the 5%% threshold - monitoring...\n", watchOpportunities)
+			logInfo.Printf("%d coins are close to the %.1f%% threshold - monitoring...\n", watchOpportunities, bot.BuyDropMin)
 		} else {
-			fmt.Println("Market is stable - no immediate opportunities")
+			logInfo.Println("Market is stable - no immediate opportunities")
 		}
 	} else {
-		fmt.Printf("Found %d BUY opportunities in the optimal 5-10%% drop range!\n", buyOpportunities)
+		logInfo.Printf("Found %d BUY opportunities in the optimal %s drop range!\n", buyOpportunities, bot.dropBandLabel())
 		if watchOpportunities > 0 {
-			fmt.Printf("Plus %d coins approaching the threshold\n", watchOpportunities)
+			logInfo.Printf("Plus %d coins approaching the threshold\n", watchOpportunities)
+		}
+	}
+	return buyOpportunities, bought
+}
+
+// nextPositionID returns the ID the next bought position will get, so callers can count buys
+func (bot *TradingBot) nextPositionID() int {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+	return bot.NextPositionID
+}
+
+// lastSellTimes returns when each symbol was last sold, from the completed trades
+func (bot *TradingBot) lastSellTimes() map[string]time.Time {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	lastSold := make(map[string]time.Time)
+	for _, trade := range bot.CompletedTrades {
+		if trade.SellTime.After(lastSold[trade.Symbol]) {
+			lastSold[trade.Symbol] = trade.SellTime
+		}
+	}
+	return lastSold
+}
+
+// checkVolumeSpike compares a coin's 24h volume against its recorded average and reports abnormal spikes
+func (bot *TradingBot) checkVolumeSpike(coin OptimizedTicker) (float64, bool) {
+	history := bot.VolumeHistory[coin.Symbol]
+	if bot.VolumeSpikeMultiplier <= 0 || len(history) < minVolumeSamples || coin.Volume24h <= 0 {
+		return 0, false
+	}
+
+	total := 0.0
+	for _, volume := range history {
+		total += volume
+	}
+	average := total / float64(len(history))
+	if average <= 0 {
+		return 0, false
+	}
+
+	ratio := coin.Volume24h / average
+	return ratio, ratio >= bot.VolumeSpikeMultiplier
+}
+
+// recordVolumes stores this cycle's 24h volumes as the rolling baseline for spike detection
+func (bot *TradingBot) recordVolumes() {
+	for _, coin := range bot.WatchList {
+		if coin.Volume24h <= 0 {
+			continue
+		}
+
+		history := append(bot.VolumeHistory[coin.Symbol], coin.Volume24h)
+		if len(history) > maxVolumeSamples {
+			history = history[len(history)-maxVolumeSamples:]
+		}
+		bot.VolumeHistory[coin.Symbol] = history
+	}
+}
+
+// avgFillPrice calculates the volume-weighted price across fills, or 0 if nothing filled
+func avgFillPrice(fills []Fill) float64 {
+	totalValue := 0.0
+	totalQty := 0.0
+	for _, fill := range fills {
+		fillPrice, _ := strconv.ParseFloat(fill.Price, 64)
+		fillQty, _ := strconv.ParseFloat(fill.Qty, 64)
+		totalValue += fillPrice * fillQty
+		totalQty += fillQty
+	}
+
+	if totalQty == 0 || totalValue == 0 {
+		return 0
+	}
+
+	return totalValue / totalQty
+}
+
+// averageFillPrice returns the average fill price of an order, or the fallback if there are no fills
+func averageFillPrice(orderResp *OrderResponse, fallback float64) float64 {
+	if price := avgFillPrice(orderResp.Fills); price > 0 {
+		return price
+	}
+	return fallback
+}
+
+// fillCommissions splits an order's commissions by asset: base-asset commission reduces the received
+// quantity, while quote-asset and BNB commissions are cash costs that leave the quantity untouched
+func fillCommissions(fills []Fill, baseAsset string) (baseQty, quoteCost, bnbCost float64) {
+	for _, fill := range fills {
+		commission, err := strconv.ParseFloat(fill.Commission, 64)
+		if err != nil || commission == 0 {
+			continue
+		}
+
+		switch fill.CommissionAsset {
+		case baseAsset:
+			baseQty += commission
+		case "BNB":
+			bnbCost += commission
+		default:
+			quoteCost += commission
 		}
 	}
+
+	return baseQty, quoteCost, bnbCost
 }
 
 // executeBuy executes real buy order on Binance mainnet - REAL MONEY!
 func (bot *TradingBot) executeBuy(coin OptimizedTicker, dropPercentage float64) {
-	// Check if we have enough budget
-	if bot.AvailableBudget < bot.InvestmentAmount {
-		fmt.Printf("Insufficient funds: Available %.2f USDT < Required %.2f USDT\n",
-			bot.AvailableBudget, bot.InvestmentAmount)
+	amount, ok := bot.reserveBuy(coin, dropPercentage)
+	if !ok || bot.PreviewMode {
+		return
+	}
+
+	// Another buy this cycle may have found Binance down for maintenance
+	if bot.inMaintenance() {
+		bot.dropBuy(amount)
+		logInfo.Printf("Skipping %s buy: trading paused while Binance is unavailable\n", strings.TrimSuffix(coin.Symbol, "USDT"))
 		return
 	}
 
-	fmt.Printf("   [BINANCE MAINNET] Executing REAL buy order...\n")
+	// The local budget can drift from the account, so never ask Binance to spend more USDT than is free
+	orderAmount := amount
+	if !bot.DryRun {
+		capped, err := bot.capToFreeUSDT(amount)
+		if err != nil {
+			logWarn.Printf("   WARNING: Could not check the free USDT balance, buying %.2f USDT anyway: %v\n", amount, err)
+		} else if capped < amount {
+			minNotional := 0.0
+			if filters, err := bot.getSymbolFilters(coin.Symbol); err == nil {
+				minNotional, _ = strconv.ParseFloat(filters.MinNotional, 64)
+			}
+			if capped <= 0 || capped < minNotional {
+				bot.dropBuy(amount)
+				logInfo.Printf("Insufficient free USDT on Binance: %.2f USDT free for a %.2f USDT buy (min %.2f) - skipping %s\n",
+					max(capped, 0), amount, minNotional, strings.TrimSuffix(coin.Symbol, "USDT"))
+				bot.explain(coin.Symbol, ReasonInsufficientFunds, "free USDT %.2f < %.2f USDT", max(capped, 0), amount)
+				logEvent(slog.LevelWarn, "buy_skipped", "symbol", coin.Symbol, "reason", "free_balance",
+					"free", capped, "requested", amount)
+				return
+			}
+			logWarn.Printf("   WARNING: Only %.2f USDT free on Binance - buying %.2f USDT instead of %.2f USDT\n",
+				capped, capped, amount)
+			orderAmount = capped
+		}
+	}
 
-	orderResp, err := bot.executeBuyOrder(coin.Symbol, bot.InvestmentAmount)
+	logInfo.Printf("   [BINANCE MAINNET] Executing REAL buy order for %s...\n", strings.TrimSuffix(coin.Symbol, "USDT"))
+	bot.markBuySent(amount)
+
+	var orderResp *OrderResponse
+	var err error
+	if bot.BuyOrderType == buyOrderLimit {
+		orderResp, err = bot.executeLimitBuyOrder(coin.Symbol, orderAmount, coin.LastPrice)
+	} else {
+		orderResp, err = bot.executeBuyOrder(coin.Symbol, orderAmount)
+	}
 	if err != nil {
-		fmt.Printf("   ERROR: Binance order failed: %v\n", err)
+		bot.releaseBuy(amount, 0)
+		logError.Printf("   ERROR: Binance order failed: %v\n", err)
+		bot.explain(coin.Symbol, ReasonOrderFailed, "%v", err)
+		logEvent(slog.LevelError, "buy_failed", "symbol", coin.Symbol, "price", coin.LastPrice, "error", err.Error())
 		return
 	} else {
 		// Parse actual executed quantity and price from Binance response
 		actualQty, _ := strconv.ParseFloat(orderResp.ExecutedQty, 64)
-		avgPrice := 0.0
-
-		// Calculate average fill price
-		if len(orderResp.Fills) > 0 {
-			totalValue := 0.0
-			totalQty := 0.0
-			for _, fill := range orderResp.Fills {
-				fillPrice, _ := strconv.ParseFloat(fill.Price, 64)
-				fillQty, _ := strconv.ParseFloat(fill.Qty, 64)
-				totalValue += fillPrice * fillQty
-				totalQty += fillQty
-			}
-			if totalQty > 0 {
-				avgPrice = totalValue / totalQty
-			}
+		avgPrice := averageFillPrice(orderResp, coin.LastPrice)
+
+		if actualQty <= 0 {
+			bot.releaseBuy(amount, 0)
+			logError.Printf("   ERROR: Buy order %d ended %s with nothing filled\n", orderResp.OrderID, orderResp.Status)
+			bot.explain(coin.Symbol, ReasonOrderFailed, "order %s with nothing filled", orderResp.Status)
+			logEvent(slog.LevelError, "buy_failed", "symbol", coin.Symbol, "price", coin.LastPrice,
+				"status", orderResp.Status, "orderId", orderResp.OrderID)
+			return
 		}
 
-		if avgPrice == 0 {
-			avgPrice = coin.LastPrice // Fallback
+		// Book what was actually spent; a thin book can leave a market buy PARTIALLY_FILLED or EXPIRED
+		invested := orderAmount
+		if spent, err := strconv.ParseFloat(orderResp.QuoteQty, 64); err == nil && spent > 0 {
+			invested = spent
+		}
+		if orderResp.Status != StatusFilled {
+			logWarn.Printf("   WARNING: Buy order %d is %s - filled %.8f for %.2f of %.2f USDT requested\n",
+				orderResp.OrderID, orderResp.Status, actualQty, invested, orderAmount)
+			logEvent(slog.LevelWarn, "buy_partial", "symbol", coin.Symbol, "status", orderResp.Status,
+				"quantity", actualQty, "spent", invested, "requested", orderAmount, "orderId", orderResp.OrderID)
 		}
 
+		// Commission taken in the base asset never reaches the account, so it can't be sold
+		baseFee, quoteFee, bnbFee := fillCommissions(orderResp.Fills, strings.TrimSuffix(coin.Symbol, "USDT"))
+		actualQty -= baseFee
+
 		position := TradingPosition{
-			ID:                 bot.NextPositionID,
 			Symbol:             coin.Symbol,
 			BuyPrice:           avgPrice,
 			Quantity:           actualQty,
-			InvestedAmount:     bot.InvestmentAmount,
-			TargetSellPrice:    avgPrice * 1.05, // Recalculate based on actual price
+			InvestedAmount:     invested,
+			TargetSellPrice:    bot.targetSellPrice(avgPrice), // Recalculate based on actual price
 			BuyTime:            time.Now(),
 			DropPercentage:     dropPercentage,
 			CurrentValue:       avgPrice * actualQty,
 			SellOrderID:        0,
 			HasActiveSellOrder: false,
+			FeesPaid:           quoteFee + bot.bnbFeeValue(bnbFee),
+			BaseFeesPaid:       baseFee,
+			HighestPrice:       avgPrice,
+			BNBFeesPaid:        bnbFee,
 		}
 
-		// Wait a moment for the buy order to fully settle before placing sell order
-		fmt.Printf("   [BINANCE MAINNET] Waiting 3 seconds for buy order to settle...\n")
-		time.Sleep(3 * time.Second)
+		if bot.ExitOrderType == exitOrderMarket {
+			logInfo.Printf("   [EXIT MODE: MARKET] No resting sell order - will market-sell once price reaches $%.6f\n",
+				position.TargetSellPrice)
+		} else {
+			bot.placeTargetSellOrder(&position)
+		}
 
-		// Place a limit sell order at target price
-		fmt.Printf("   [BINANCE MAINNET] Attempting to place sell order for %.6f %s at $%.6f\n",
-			actualQty, strings.TrimSuffix(coin.Symbol, "USDT"), position.TargetSellPrice)
+		availableBudget := bot.addPosition(&position, amount, invested)
+
+		logInfo.Printf("   [BINANCE MAINNET] SUCCESS: Buy order executed! ID: %d\n", orderResp.OrderID)
+		logInfo.Printf("   Bought %.6f %s at $%.4f avg (Investment: %.2f USDT)\n",
+			actualQty, strings.TrimSuffix(coin.Symbol, "USDT"), avgPrice, invested)
+		logInfo.Printf("   Target sell price: $%.4f (gross +%.2f%% / net +%.2f%% after %.3f%% buy + %.3f%% sell fees)\n",
+			position.TargetSellPrice, (position.TargetSellPrice/avgPrice-1)*100, bot.ProfitTargetPercent,
+			bot.buyFeePercent(), bot.sellFeePercent())
+		logInfo.Printf("   Available budget: %.2f USDT remaining\n", availableBudget)
+		bot.explain(coin.Symbol, ReasonBought, "%.2f%% drop, %.6f at $%.6f, target $%.6f",
+			dropPercentage, actualQty, avgPrice, position.TargetSellPrice)
+		bot.notify(buyMessage(position))
+		logEvent(slog.LevelInfo, "buy", "symbol", position.Symbol, "price", position.BuyPrice,
+			"quantity", position.Quantity, "invested", position.InvestedAmount, "target", position.TargetSellPrice,
+			"drop", dropPercentage, "orderId", orderResp.OrderID)
+	}
+}
 
-		// Get symbol filters to ensure proper price formatting
-		filters, filterErr := bot.getSymbolFilters(coin.Symbol)
-		if filterErr != nil {
-			fmt.Printf("   WARNING: Could not get symbol filters: %v\n", filterErr)
-			fmt.Printf("   INFO: Position will be monitored manually for sell opportunities\n")
-		} else {
-			// Round the target sell price to conform to Binance tick size
-			roundedSellPrice := roundToTickSize(position.TargetSellPrice, filters.TickSize)
-			fmt.Printf("   [PRICE ADJUSTMENT] Original: $%.6f -> Rounded: $%.6f (TickSize: %s)\n",
-				position.TargetSellPrice, roundedSellPrice, filters.TickSize)
-
-			// Try to place the sell order with retry logic
-			maxRetries := 3
-			var sellOrderResp *OrderResponse
-			var sellErr error
-
-			for retry := 1; retry <= maxRetries; retry++ {
-				sellOrderResp, sellErr = bot.executeLimitSellOrder(coin.Symbol, actualQty, roundedSellPrice)
-				if sellErr == nil {
-					break
-				}
+// reserveBuy runs the budget, position, exposure and min notional checks for a buy and, if they pass,
+// sets its amount aside so concurrent buys can't spend the same budget. Preview mode only reports the buy.
+func (bot *TradingBot) reserveBuy(coin OptimizedTicker, dropPercentage float64) (float64, bool) {
+	minNotional := 0.0
+	if filters, err := bot.getSymbolFilters(coin.Symbol); err == nil {
+		minNotional, _ = strconv.ParseFloat(filters.MinNotional, 64)
+	}
 
-				fmt.Printf("   RETRY %d/%d: Sell order failed: %v\n", retry, maxRetries, sellErr)
-				if retry < maxRetries {
-					fmt.Printf("   Waiting 2 seconds before retry...\n")
-					time.Sleep(2 * time.Second)
-				}
-			}
+	bot.stateMu.Lock()
+	defer bot.stateMu.Unlock()
 
-			if sellErr != nil {
-				fmt.Printf("   WARNING: Failed to place automatic sell order after %d attempts: %v\n", maxRetries, sellErr)
-				fmt.Printf("   INFO: Position will be monitored manually for sell opportunities\n")
-			} else {
-				position.SellOrderID = sellOrderResp.OrderID
-				position.HasActiveSellOrder = true
-				position.TargetSellPrice = roundedSellPrice // Update to the actual rounded price
-				fmt.Printf("   [BINANCE MAINNET] SUCCESS: Sell order placed! ID: %d at $%.6f\n",
-					sellOrderResp.OrderID, roundedSellPrice)
-			}
-		}
+	amount := bot.sizeForDrop(bot.tradeAmount(minNotional), dropPercentage, minNotional)
 
-		bot.Positions = append(bot.Positions, position)
-		bot.AvailableBudget -= bot.InvestmentAmount
-		bot.NextPositionID++
+	// Check if we have enough budget
+	if amount <= 0 || bot.AvailableBudget < amount {
+		required := max(amount, minNotional)
+		logInfo.Printf("Insufficient funds: Available %.2f USDT < Required %.2f USDT\n",
+			bot.AvailableBudget, required)
+		bot.explain(coin.Symbol, ReasonInsufficientFunds, "available %.2f < %.2f USDT",
+			bot.AvailableBudget, required)
+		return 0, false
+	}
 
-		fmt.Printf("   [BINANCE MAINNET] SUCCESS: Buy order executed! ID: %d\n", orderResp.OrderID)
-		fmt.Printf("   Bought %.6f %s at $%.4f avg (Investment: %.2f USDT)\n",
-			actualQty, strings.TrimSuffix(coin.Symbol, "USDT"), avgPrice, bot.InvestmentAmount)
-		fmt.Printf("   Target sell price: $%.4f (+5%% profit)\n", position.TargetSellPrice)
-		fmt.Printf("   Available budget: %.2f USDT remaining\n", bot.AvailableBudget)
+	// Cap the number of open positions (sold positions are removed during reconciliation)
+	openPositions := len(bot.Positions) + bot.pendingBuys
+	if bot.MaxOpenPositions > 0 && openPositions >= bot.MaxOpenPositions {
+		logInfo.Printf("Position limit reached: %d/%d open positions - skipping %s\n",
+			openPositions, bot.MaxOpenPositions, strings.TrimSuffix(coin.Symbol, "USDT"))
+		bot.explain(coin.Symbol, ReasonPositionLimit, "%d/%d open positions", openPositions, bot.MaxOpenPositions)
+		return 0, false
 	}
-}
 
-// getCurrentPortfolioValue calculates the current value of all positions
-func (bot *TradingBot) getCurrentPortfolioValue() float64 {
-	totalValue := 0.0
-	for _, pos := range bot.Positions {
-		totalValue += pos.CurrentValue
+	// Cap the total invested across open positions so a broad dip can't take the whole budget
+	if exposure := bot.totalExposure() + bot.pendingBuyUSDT; bot.MaxTotalExposure > 0 && exposure+amount > bot.MaxTotalExposure {
+		logInfo.Printf("Exposure limit reached: %.2f USDT invested + %.2f USDT > cap %.2f USDT - skipping %s\n",
+			exposure, amount, bot.MaxTotalExposure, strings.TrimSuffix(coin.Symbol, "USDT"))
+		bot.explain(coin.Symbol, ReasonExposureLimit, "exposure %.2f + %.2f > cap %.2f USDT",
+			exposure, amount, bot.MaxTotalExposure)
+		return 0, false
 	}
-	return totalValue
+
+	// Binance rejects orders below the symbol's minimum notional, so don't send a doomed order
+	if minNotional > amount {
+		logInfo.Printf("Skipping %s: minimum order %.2f USDT exceeds investment amount %.2f USDT\n",
+			strings.TrimSuffix(coin.Symbol, "USDT"), minNotional, amount)
+		bot.explain(coin.Symbol, ReasonBelowMinNotional, "min notional %.2f > %.2f USDT", minNotional, amount)
+		return 0, false
+	}
+
+	bot.AvailableBudget -= amount
+
+	if bot.PreviewMode {
+		targetPrice := bot.targetSellPrice(coin.LastPrice)
+		logInfo.Printf("   [PREVIEW] Would buy %.2f USDT of %s at ~$%.6f, target sell $%.6f\n",
+			amount, strings.TrimSuffix(coin.Symbol, "USDT"), coin.LastPrice, targetPrice)
+		bot.explain(coin.Symbol, ReasonWouldBuy, "%.2f%% drop, %.2f USDT at ~$%.6f, target $%.6f",
+			dropPercentage, amount, coin.LastPrice, targetPrice)
+		return amount, true
+	}
+
+	bot.pendingBuys++
+	bot.pendingBuyUSDT += amount
+	bot.unsentBuyUSDT += amount
+	return amount, true
 }
 
-// runTradingCycle executes one complete trading cycle with optimized CMC+Binance integration
-func (bot *TradingBot) runTradingCycle() error {
-	fmt.Printf("\n" + strings.Repeat("=", 80))
-	fmt.Printf("\nOptimized Trading Bot Cycle - %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Printf("Data Source: CoinMarketCap API (Top 20, excluding stablecoins)\n")
-	fmt.Printf("Trading Platform: Binance (buy/sell execution only)\n")
-	fmt.Printf("Strategy: Buy 5-10%% drops, Sell at +5%% profit\n")
-	fmt.Printf(strings.Repeat("=", 80))
-
-	// Fetch current market data from CoinMarketCap top 20 (optimized - no extra Binance calls)
-	watchList, err := bot.fetchTop20CoinsFromCMC()
+// capToFreeUSDT caps a reserved buy amount to the free USDT on the Binance account, less what the other
+// buys have reserved but not yet sent so concurrent buys can't all count the same balance. Orders already
+// sent have left the free balance, so they aren't subtracted again.
+func (bot *TradingBot) capToFreeUSDT(amount float64) (float64, error) {
+	free, err := bot.Binance.AccountBalance("USDT")
 	if err != nil {
-		return fmt.Errorf("failed to fetch CoinMarketCap top 20: %v", err)
+		return amount, err
 	}
 
-	bot.WatchList = watchList
-	fmt.Printf("\nMonitoring %d non-stablecoin coins from CoinMarketCap top 50\n", len(bot.WatchList))
+	bot.stateMu.RLock()
+	otherUnsent := max(bot.unsentBuyUSDT-amount, 0)
+	bot.stateMu.RUnlock()
 
-	// Analyze new buy opportunities using CMC data
-	bot.analyzeTradingOpportunities()
+	return min(amount, free-otherUnsent), nil
+}
 
-	return nil
+// markBuySent records that the order for a reservation from reserveBuy is being sent to Binance
+func (bot *TradingBot) markBuySent(reserved float64) {
+	bot.stateMu.Lock()
+	defer bot.stateMu.Unlock()
+
+	bot.unsentBuyUSDT -= reserved
 }
 
-// startBot starts the trading bot with 60-minute cycles for testing
-func (bot *TradingBot) startBot() {
-	fmt.Println("Starting Trading Bot...")
-	fmt.Printf("Strategy: Buy on drops between -5%% to -10%% | Sell at +5%% profit\n")
-	fmt.Printf("Budget: %.2f USDT | Investment per trade: %.2f USDT\n", bot.TotalBudget, bot.InvestmentAmount)
-	fmt.Printf("Cycle frequency: Every 60 minutes for active testing\n")
+// dropBuy ends a reservation from reserveBuy whose order was never sent
+func (bot *TradingBot) dropBuy(reserved float64) {
+	bot.markBuySent(reserved)
+	bot.releaseBuy(reserved, 0)
+}
 
-	// Run initial cycle
-	if err := bot.runTradingCycle(); err != nil {
-		log.Printf("Error in trading cycle: %v", err)
+// releaseBuy ends a reservation from reserveBuy, returning whatever wasn't spent to the budget
+func (bot *TradingBot) releaseBuy(reserved, spent float64) {
+	bot.stateMu.Lock()
+	defer bot.stateMu.Unlock()
+
+	bot.AvailableBudget += reserved - spent
+	bot.pendingBuys--
+	bot.pendingBuyUSDT -= reserved
+}
+
+// addPosition records a filled buy, settling its reservation and assigning the next position ID.
+// It returns the available budget afterwards.
+func (bot *TradingBot) addPosition(position *TradingPosition, reserved, spent float64) float64 {
+	bot.stateMu.Lock()
+
+	position.ID = bot.NextPositionID
+	bot.NextPositionID++
+	bot.Positions = append(bot.Positions, *position)
+	bot.AvailableBudget += reserved - spent
+	bot.pendingBuys--
+	bot.pendingBuyUSDT -= reserved
+	availableBudget := bot.AvailableBudget
+	bot.stateMu.Unlock()
+
+	bot.saveState()
+	return availableBudget
+}
+
+// dailyRealizedPnL returns the profit booked by trades closed since local midnight
+func (bot *TradingBot) dailyRealizedPnL() float64 {
+	midnight := startOfDay(time.Now())
+
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	total := 0.0
+	for _, trade := range bot.CompletedTrades {
+		if !trade.SellTime.Before(midnight) {
+			total += trade.Profit
+		}
 	}
+	return total
+}
 
-	// Set up 60-minute ticker for testing
-	ticker := time.NewTicker(60 * time.Minute)
-	defer ticker.Stop()
+// startOfDay returns local midnight on t's day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
 
-	fmt.Println("\nBot will run every 60 minutes. Press Ctrl+C to stop.")
+// dailyLossHalted reports whether today's realized loss has reached MAX_DAILY_LOSS_USDT. New buys stay halted
+// until midnight, while open positions and their sell orders carry on; the halt is announced once per day.
+func (bot *TradingBot) dailyLossHalted() bool {
+	if bot.MaxDailyLoss <= 0 {
+		return false
+	}
 
-	for {
-		select {
-		case <-ticker.C:
-			if err := bot.runTradingCycle(); err != nil {
-				log.Printf("Error in trading cycle: %v", err)
+	dailyPnL := bot.dailyRealizedPnL()
+	if -dailyPnL < bot.MaxDailyLoss {
+		return false
+	}
+
+	logInfo.Printf("\n!!! DAILY LOSS LIMIT HIT: %.2f USDT lost today (limit %.2f USDT) - no new buys until midnight !!!\n",
+		-dailyPnL, bot.MaxDailyLoss)
+
+	if today := startOfDay(time.Now()); !bot.dailyHaltAlerted.Equal(today) {
+		bot.dailyHaltAlerted = today
+		log.Printf("WARNING: Daily loss limit reached (%.2f of %.2f USDT) - buying halted until midnight", -dailyPnL, bot.MaxDailyLoss)
+		logEvent(slog.LevelWarn, "daily_loss_halt", "loss", -dailyPnL, "limit", bot.MaxDailyLoss)
+		bot.notify(fmt.Sprintf("DAILY LOSS LIMIT: %.2f USDT lost today (limit %.2f USDT) - new buys halted until midnight, open positions keep their sell orders",
+			-dailyPnL, bot.MaxDailyLoss))
+	}
+	return true
+}
+
+// executeBuys places the buys found by the analysis with up to BuyConcurrency orders in flight.
+// Each buy blocks for seconds (fills, settlement, sell placement), so this keeps cycles short.
+func (bot *TradingBot) executeBuys(candidates []OptimizedTicker) {
+	if len(candidates) == 0 {
+		return
+	}
+
+	jobs := make(chan OptimizedTicker)
+	var wg sync.WaitGroup
+	for range min(max(bot.BuyConcurrency, 1), len(candidates)) {
+		wg.Go(func() {
+			for coin := range jobs {
+				bot.executeBuy(coin, coin.PriceChangePercent)
 			}
+		})
+	}
+
+	for _, coin := range candidates {
+		// A buy costs several requests (order, balance polls, sell), so check the weight budget first
+		bot.throttleRequestWeight()
+		if !bot.PreviewMode {
+			logInfo.Printf("Executing REAL trade: %s of %s at $%.4f\n",
+				bot.investmentLabel(), strings.TrimSuffix(coin.Symbol, "USDT"), coin.LastPrice)
 		}
+		jobs <- coin
 	}
+	close(jobs)
+	wg.Wait()
 }
 
-// StartTradingBot is the entry point for the optimized trading bot
-func StartTradingBot() {
-	fmt.Println("=== OPTIMIZED Crypto Trading Bot ===")
-	fmt.Println("Data Strategy: CoinMarketCap API (Top 20 non-stablecoins)")
-	fmt.Println("Trading Strategy: 5-10% drops → 5% profit target")
-	fmt.Println("Execution Platform: Binance API (buy/sell only)")
+// tradeAmount returns the USDT to invest in the next trade. Percent mode takes INVESTMENT_PERCENT of the
+// available budget, raised to the symbol's minimum notional and capped at the available budget.
+// MAX_TRADE_USDT caps the result in either mode. The caller must hold stateMu while buys can run.
+func (bot *TradingBot) tradeAmount(minNotional float64) float64 {
+	amount := bot.InvestmentAmount
+	if bot.InvestmentMode == investmentModePercent {
+		amount = bot.AvailableBudget * bot.InvestmentPercent / 100
+		amount = math.Max(amount, minNotional)
+		amount = math.Min(amount, bot.AvailableBudget)
+	}
 
-	// Check API credentials first
-	apiKey := os.Getenv("BINANCE_API_KEY")
-	secretKey := os.Getenv("BINANCE_SECRET_KEY")
-	cmcKey := os.Getenv("COIN_MARKET_CAP_API_KEY")
+	if bot.MaxTradeUSDT > 0 {
+		amount = math.Min(amount, bot.MaxTradeUSDT)
+	}
+	return amount
+}
 
-	if apiKey == "" || secretKey == "" {
-		log.Fatalf("ERROR: BINANCE API KEYS REQUIRED! Set BINANCE_API_KEY and BINANCE_SECRET_KEY in .env file")
+// dropSizeMultiplier scales the trade amount with the drop depth in drop sizing mode: 1x at BuyDropMin
+// rising to DropSizingMax at BuyDropMax, shaped by DropSizingCurve. Flat mode always returns 1.
+func (bot *TradingBot) dropSizeMultiplier(dropPercentage float64) float64 {
+	if bot.SizingMode != sizingModeDrop || bot.BuyDropMax >= bot.BuyDropMin {
+		return 1
+	}
+
+	// Both thresholds are negative, so depth runs from 0 at BuyDropMin to 1 at BuyDropMax
+	depth := (bot.BuyDropMin - dropPercentage) / (bot.BuyDropMin - bot.BuyDropMax)
+	depth = math.Max(0, math.Min(1, depth))
+
+	curve := bot.DropSizingCurve
+	if curve <= 0 {
+		curve = 1
 	}
+	return 1 + (bot.DropSizingMax-1)*math.Pow(depth, curve)
+}
 
-	if cmcKey == "" {
-		log.Fatalf("ERROR: COINMARKETCAP API KEY REQUIRED! Set COIN_MARKET_CAP_API_KEY in .env file")
+// sizeForDrop applies the drop sizing multiplier to a trade amount, keeping the result at or above the
+// minimum notional and within MAX_TRADE_USDT and the available budget. It returns 0 when the budget can't
+// cover the minimum notional, so the trade is skipped. The caller must hold stateMu.
+func (bot *TradingBot) sizeForDrop(amount, dropPercentage, minNotional float64) float64 {
+	multiplier := bot.dropSizeMultiplier(dropPercentage)
+	if multiplier == 1 {
+		return amount
 	}
 
-	// Fetch real USDT balance from Binance
-	fmt.Println("\nFetching real USDT balance from Binance...")
+	amount = math.Max(amount*multiplier, minNotional)
+	if bot.MaxTradeUSDT > 0 {
+		amount = math.Min(amount, bot.MaxTradeUSDT)
+	}
 
-	realBalance, err := getRealUSDTBalance(apiKey, secretKey)
-	if err != nil {
-		log.Fatalf("ERROR: Failed to fetch real USDT balance: %v", err)
+	// Capping at the budget must not undo the raise to the minimum notional, or Binance rejects the order
+	if amount > bot.AvailableBudget {
+		if bot.AvailableBudget < minNotional {
+			return 0
+		}
+		amount = bot.AvailableBudget
+	}
+	return amount
+}
+
+// totalExposure returns the USDT invested across all open positions. The caller must hold stateMu.
+func (bot *TradingBot) totalExposure() float64 {
+	exposure := 0.0
+	for _, pos := range bot.Positions {
+		exposure += pos.InvestedAmount
+	}
+	return exposure
+}
+
+// investmentLabel describes the per-trade sizing for console output
+func (bot *TradingBot) investmentLabel() string {
+	label := fmt.Sprintf("%.2f USDT", bot.InvestmentAmount)
+	if bot.InvestmentMode == investmentModePercent {
+		label = fmt.Sprintf("%.1f%% of available budget", bot.InvestmentPercent)
+	}
+	if bot.SizingMode == sizingModeDrop {
+		label += fmt.Sprintf(", scaled up to %.1fx for drops near %.1f%%", bot.DropSizingMax, bot.BuyDropMax)
 	}
+	return label
+}
 
-	fmt.Printf("SUCCESS: Real USDT Balance: %.2f USDT\n", realBalance)
+// buyFeePercent returns the effective fee on entries (market buys are always taker orders)
+func (bot *TradingBot) buyFeePercent() float64 {
+	return bot.applyBNBDiscount(bot.TakerFeePercent)
+}
 
-	if realBalance < 7.0 {
-		log.Fatalf("ERROR: Insufficient USDT balance (%.2f). Need at least 7 USDT for trading.", realBalance)
+// sellFeePercent returns the effective fee on exits: maker for resting limit sells, taker for market exits
+func (bot *TradingBot) sellFeePercent() float64 {
+	if bot.ExitOrderType == exitOrderMarket {
+		return bot.applyBNBDiscount(bot.TakerFeePercent)
 	}
+	return bot.applyBNBDiscount(bot.MakerFeePercent)
+}
 
-	if realBalance < 20.0 {
-		fmt.Printf("WARNING: Low balance detected (%.2f USDT). Consider reducing INVESTMENT_PER_TRADE.\n", realBalance)
+// bnbPrice returns the BNB/USDT price used to value BNB commissions, fetched at most once per cycle
+func (bot *TradingBot) bnbPrice() (float64, error) {
+	bot.bnbPriceMu.Lock()
+	defer bot.bnbPriceMu.Unlock()
+
+	if bot.bnbPriceCache > 0 {
+		return bot.bnbPriceCache, nil
 	}
 
-	// Initialize bot using real balance
-	bot, err := NewTradingBot(realBalance)
+	price, err := bot.getTickerPrice("BNBUSDT")
 	if err != nil {
-		log.Fatalf("Failed to initialize trading bot: %v", err)
+		return 0, fmt.Errorf("error getting BNB price: %v", err)
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("invalid BNB price %v", price)
+	}
+
+	bot.bnbPriceCache = price
+	return price, nil
+}
+
+// bnbFeeValue converts a commission paid in BNB to its USDT equivalent so P/L includes it
+func (bot *TradingBot) bnbFeeValue(bnbAmount float64) float64 {
+	if bnbAmount <= 0 {
+		return 0
+	}
+
+	price, err := bot.bnbPrice()
+	if err != nil {
+		log.Printf("WARNING: BNB fee of %.8f BNB left out of P/L: %v", bnbAmount, err)
+		return 0
+	}
+
+	return bnbAmount * price
+}
+
+// applyBNBDiscount reduces a fee rate when fees are paid in BNB
+func (bot *TradingBot) applyBNBDiscount(feePercent float64) float64 {
+	if bot.BNBFeeDiscount {
+		return feePercent * (1 - bnbFeeDiscountPercent/100)
+	}
+	return feePercent
+}
+
+// targetSellPrice computes the sell price that nets ProfitTargetPercent after buy and sell fees
+func (bot *TradingBot) targetSellPrice(buyPrice float64) float64 {
+	return bot.targetSellPriceAt(buyPrice, bot.ProfitTargetPercent)
+}
+
+// targetSellPriceAt returns the sell price that nets netPercent profit on buyPrice after buy and sell fees
+func (bot *TradingBot) targetSellPriceAt(buyPrice, netPercent float64) float64 {
+	buyFee := bot.buyFeePercent() / 100
+	sellFee := bot.sellFeePercent() / 100
+	netTarget := netPercent / 100
+
+	// Proceeds after the sell fee must cover the investment, the buy fee, and the net profit
+	return buyPrice * (1 + buyFee + netTarget) / (1 - sellFee)
+}
+
+// placeTargetSellOrder places the resting limit sell at the position's target price
+func (bot *TradingBot) placeTargetSellOrder(position *TradingPosition) {
+	// Wait for the bought quantity to show up as free balance before placing sell order
+	baseAsset := strings.TrimSuffix(position.Symbol, "USDT")
+	if !bot.waitForSettledBalance(baseAsset, position.Quantity) {
+		logWarn.Printf("   WARNING: %s balance not confirmed within %s, attempting sell order anyway\n",
+			baseAsset, bot.SellPlaceTimeout)
+	}
+
+	// Place a limit sell order at target price
+	logInfo.Printf("   [BINANCE MAINNET] Attempting to place sell order for %.6f %s at $%.6f\n",
+		position.Quantity, strings.TrimSuffix(position.Symbol, "USDT"), position.TargetSellPrice)
+
+	// Get symbol filters to ensure proper price formatting
+	filters, filterErr := bot.getSymbolFilters(position.Symbol)
+	if filterErr != nil {
+		logWarn.Printf("   WARNING: Could not get symbol filters: %v\n", filterErr)
+		logInfo.Printf("   INFO: Position will be monitored manually for sell opportunities\n")
+	} else {
+		// Round the target sell price to conform to Binance tick size
+		roundedSellPrice := roundToTickSize(position.TargetSellPrice, filters.TickSize, bot.SellRounding)
+		logInfo.Printf("   [PRICE ADJUSTMENT] Original: $%.6f -> Rounded: $%.6f (TickSize: %s)\n",
+			position.TargetSellPrice, roundedSellPrice, filters.TickSize)
+
+		// Only whole LOT_SIZE steps can be sold; any remainder stays behind as dust
+		roundedQuantity := roundToStepSize(position.Quantity, filters.StepSize)
+		if roundedQuantity != position.Quantity {
+			logInfo.Printf("   [QUANTITY ADJUSTMENT] Original: %.8f -> Rounded: %.8f (StepSize: %s)\n",
+				position.Quantity, roundedQuantity, filters.StepSize)
+			position.Quantity = roundedQuantity
+		}
+
+		// On very low-priced coins the rounded target can land on the buy price, wiping out the profit
+		if adjusted, bumped := targetAboveBuyPrice(roundedSellPrice, position.BuyPrice, filters.TickSize); bumped {
+			logInfo.Printf("   [PRICE ADJUSTMENT] Rounded target $%.8f is not a tick above the buy price $%.8f - raised to $%.8f\n",
+				roundedSellPrice, position.BuyPrice, adjusted)
+			logEvent(slog.LevelWarn, "target_bumped", "symbol", position.Symbol, "buyPrice", position.BuyPrice,
+				"rounded", roundedSellPrice, "target", adjusted, "tickSize", filters.TickSize)
+			roundedSellPrice = adjusted
+		}
+
+		// Rounding, spreads and fees can leave a target that only looks profitable; leave those to the user
+		if profit := bot.netProfitAt(*position, roundedSellPrice); profit <= 0 || profit < bot.MinNetProfit {
+			logWarn.Printf("   WARNING: Selling at $%.8f would net %+.4f USDT after fees (minimum %.4f USDT) - not placing a sell order\n",
+				roundedSellPrice, profit, bot.MinNetProfit)
+			logInfo.Printf("   INFO: Position will be monitored manually for sell opportunities\n")
+			logEvent(slog.LevelWarn, "sell_unprofitable", "symbol", position.Symbol, "price", roundedSellPrice,
+				"quantity", position.Quantity, "netProfit", profit, "minNetProfit", bot.MinNetProfit)
+			return
+		}
+
+		// The OCO stop leg triggers at the stop-loss and sells with a little room below it
+		stopPrice := roundToTickSize(position.BuyPrice*(1-bot.StopLossPercent/100), filters.TickSize, RoundNearest)
+		stopLimitPrice := roundToTickSize(stopPrice*(1-ocoStopLimitBufferPercent/100), filters.TickSize, RoundNearest)
+
+		// Scale out in several limit sells when configured, falling back to one if the tranches can't be placed
+		if len(bot.SellTranches) > 0 && bot.placeSellTranches(position, filters) {
+			return
+		}
+
+		// Try to place the sell order with retry logic
+		var sellOrderResp *OrderResponse
+		var ocoResp *OCOResponse
+		sellErr := bot.retrySellOrder(func() (err error) {
+			if bot.UseOCO {
+				ocoResp, err = bot.executeOCOSellOrder(position.Symbol, position.Quantity, roundedSellPrice, stopPrice, stopLimitPrice)
+			} else {
+				sellOrderResp, err = bot.executeLimitSellOrder(position.Symbol, position.Quantity, roundedSellPrice)
+			}
+			return err
+		})
+
+		if sellErr != nil {
+			logWarn.Printf("   WARNING: Failed to place automatic sell order: %v\n", sellErr)
+			logInfo.Printf("   INFO: Position will be monitored manually for sell opportunities\n")
+		} else if ocoResp != nil {
+			for _, leg := range ocoResp.OrderReports {
+				switch leg.Type {
+				case OrderTypeLimitMaker:
+					position.SellOrderID = leg.OrderID
+				case OrderTypeStopLossLimit:
+					position.StopOrderID = leg.OrderID
+				}
+			}
+			position.OCOOrderListID = ocoResp.OrderListID
+			position.HasActiveSellOrder = position.SellOrderID != 0
+			position.TargetSellPrice = roundedSellPrice
+			logInfo.Printf("   [BINANCE MAINNET] SUCCESS: OCO sell placed! List %d: target order %d at $%.6f, stop order %d at $%.6f (limit $%.6f)\n",
+				ocoResp.OrderListID, position.SellOrderID, roundedSellPrice, position.StopOrderID, stopPrice, stopLimitPrice)
+		} else {
+			position.SellOrderID = sellOrderResp.OrderID
+			position.HasActiveSellOrder = true
+			position.TargetSellPrice = roundedSellPrice // Update to the actual rounded price
+			logInfo.Printf("   [BINANCE MAINNET] SUCCESS: Sell order placed! ID: %d at $%.6f\n",
+				sellOrderResp.OrderID, roundedSellPrice)
+		}
+	}
+}
+
+// retrySellOrder calls place until it succeeds, retrying transient failures up to SellMaxRetries times with backoff
+func (bot *TradingBot) retrySellOrder(place func() error) error {
+	var err error
+	for retry := 1; retry <= bot.SellMaxRetries; retry++ {
+		if err = place(); err == nil {
+			return nil
+		}
+
+		// Insufficient balance (e.g. already sold elsewhere) or a filter failure won't change on retry
+		if !isRetryableOrderError(err) {
+			logInfo.Printf("   Sell order rejected, not retrying: %v\n", err)
+			return err
+		}
+
+		logInfo.Printf("   RETRY %d/%d: Sell order failed: %v\n", retry, bot.SellMaxRetries, err)
+		if retry < bot.SellMaxRetries {
+			delay := backoffDelay(bot.SellRetryBackoff, retry)
+			if binanceErrorCode(err) == binanceCodeTooManyRequests {
+				// The weight limit only lifts when the minute window resets
+				delay = max(delay, weightResetDelay())
+			}
+			logInfo.Printf("   Waiting %s before retry...\n", delay)
+			time.Sleep(delay)
+		}
+	}
+	return err
+}
+
+// placeSellTranches scales out of a position with one limit sell per SELL_TRANCHES entry, each rounded to
+// the step size with the last taking the remainder. It reports false without placing anything when a
+// tranche would be under the minimum notional or lose money, or the tranches together would net less than
+// MIN_NET_PROFIT_USDT. It also reports false when any tranche order failed, after cancelling those placed,
+// so the caller can place a single sell instead.
+func (bot *TradingBot) placeSellTranches(position *TradingPosition, filters *SymbolFilters) bool {
+	coinName := strings.TrimSuffix(position.Symbol, "USDT")
+	minNotional, _ := strconv.ParseFloat(filters.MinNotional, 64)
+
+	planned := make([]TrancheOrder, 0, len(bot.SellTranches))
+	remaining := position.Quantity
+	totalProfit := 0.0
+	for i, tranche := range bot.SellTranches {
+		quantity := roundToStepSize(position.Quantity*tranche.Fraction, filters.StepSize)
+		if i == len(bot.SellTranches)-1 {
+			quantity = roundToStepSize(remaining, filters.StepSize)
+		}
+		price := roundToTickSize(bot.targetSellPriceAt(position.BuyPrice, tranche.ProfitPercent), filters.TickSize, bot.SellRounding)
+
+		if quantity <= 0 || quantity*price < minNotional {
+			logWarn.Printf("   WARNING: %s tranche %d (%.8f at $%.8f) is under the %.2f USDT minimum order - placing a single sell\n",
+				coinName, i+1, quantity, price, minNotional)
+			return false
+		}
+		sold, _ := splitPosition(*position, quantity)
+		profit := bot.netProfitAt(sold, price)
+		if profit <= 0 {
+			logWarn.Printf("   WARNING: %s tranche %d at $%.8f would net %+.4f USDT after fees - placing a single sell\n",
+				coinName, i+1, price, profit)
+			return false
+		}
+		totalProfit += profit
+
+		planned = append(planned, TrancheOrder{Price: price, Quantity: quantity})
+		remaining -= quantity
+	}
+
+	// The single-sell minimum was checked at the full target, but the lower tranches bring in less
+	if totalProfit < bot.MinNetProfit {
+		logWarn.Printf("   WARNING: %s tranches would net %+.4f USDT in total, below MIN_NET_PROFIT_USDT %.4f - placing a single sell\n",
+			coinName, totalProfit, bot.MinNetProfit)
+		return false
+	}
+
+	for i := range planned {
+		tranche := &planned[i]
+		var orderResp *OrderResponse
+		err := bot.retrySellOrder(func() (err error) {
+			orderResp, err = bot.executeLimitSellOrder(position.Symbol, tranche.Quantity, tranche.Price)
+			return err
+		})
+		if err != nil {
+			logWarn.Printf("   WARNING: Failed to place %s sell tranche %d (%.8f at $%.6f): %v\n",
+				coinName, i+1, tranche.Quantity, tranche.Price, err)
+			continue
+		}
+
+		tranche.OrderID = orderResp.OrderID
+		position.Tranches = append(position.Tranches, *tranche)
+		logInfo.Printf("   [BINANCE MAINNET] SUCCESS: Sell tranche %d/%d placed! ID: %d, %.8f %s at $%.6f\n",
+			i+1, len(planned), orderResp.OrderID, tranche.Quantity, coinName, tranche.Price)
+	}
+
+	if len(position.Tranches) == 0 {
+		logWarn.Printf("   WARNING: No %s sell tranche could be placed - placing a single sell\n", coinName)
+		return false
+	}
+	if len(position.Tranches) < len(planned) {
+		// Quantity without an order would never sell while the placed tranches keep the position active
+		if err := bot.cancelSellOrders(position); err != nil {
+			logWarn.Printf("   WARNING: Only %d of %d %s sell tranches placed and the rest could not be cancelled: %v\n",
+				len(position.Tranches), len(planned), coinName, err)
+		} else {
+			logWarn.Printf("   WARNING: Only some %s sell tranches could be placed - cancelled them, placing a single sell\n", coinName)
+			return false
+		}
+	}
+	position.SellOrderID = position.Tranches[0].OrderID
+	position.HasActiveSellOrder = true
+	position.TargetSellPrice = position.Tranches[len(position.Tranches)-1].Price
+	return true
+}
+
+// splitPosition splits quantity off a position for a partial sale, sharing its cost and fees pro rata
+func splitPosition(pos TradingPosition, quantity float64) (sold, rest TradingPosition) {
+	share := 1.0
+	if pos.Quantity > 0 {
+		share = min(quantity/pos.Quantity, 1)
+	}
+
+	sold, rest = pos, pos
+	sold.Quantity = quantity
+	sold.InvestedAmount = pos.InvestedAmount * share
+	sold.FeesPaid = pos.FeesPaid * share
+	sold.BaseFeesPaid = pos.BaseFeesPaid * share
+	sold.BNBFeesPaid = pos.BNBFeesPaid * share
+	sold.Tranches = nil
+
+	rest.Quantity = pos.Quantity - quantity
+	rest.InvestedAmount = pos.InvestedAmount - sold.InvestedAmount
+	rest.FeesPaid = pos.FeesPaid - sold.FeesPaid
+	rest.BaseFeesPaid = pos.BaseFeesPaid - sold.BaseFeesPaid
+	rest.BNBFeesPaid = pos.BNBFeesPaid - sold.BNBFeesPaid
+	return sold, rest
+}
+
+// cancelSellOrders cancels a position's resting sells - the limit or OCO sell, or every open tranche - and
+// clears them from the position. Cancelling one OCO leg cancels the whole list. Anything an order sold
+// before it was cancelled is booked and taken off the position, so an exit only sells what is left;
+// the position's Quantity is 0 when nothing is.
+func (bot *TradingBot) cancelSellOrders(pos *TradingPosition) error {
+	if len(pos.Tranches) > 0 {
+		for len(pos.Tranches) > 0 {
+			tranche := pos.Tranches[0]
+			cancelled, err := bot.cancelOrder(pos.Symbol, tranche.OrderID)
+			if err != nil {
+				pos.SellOrderID = tranche.OrderID
+				return fmt.Errorf("could not cancel sell tranche order %d: %v", tranche.OrderID, err)
+			}
+			pos.Tranches = pos.Tranches[1:]
+			bot.bookCancelledFill(pos, tranche, cancelled)
+		}
+	} else if pos.SellOrderID != 0 {
+		cancelled, err := bot.cancelOrder(pos.Symbol, pos.SellOrderID)
+		if err != nil {
+			return fmt.Errorf("could not cancel sell order %d: %v", pos.SellOrderID, err)
+		}
+		order := TrancheOrder{OrderID: pos.SellOrderID, Price: pos.TargetSellPrice, Quantity: pos.Quantity}
+		bot.bookCancelledFill(pos, order, cancelled)
+	}
+
+	pos.Tranches = nil
+	pos.HasActiveSellOrder = false
+	pos.SellOrderID = 0
+	pos.StopOrderID = 0
+	pos.OCOOrderListID = 0
+	return nil
+}
+
+// bookCancelledFill books the part of a sell order that filled before it was cancelled and takes it off the position
+func (bot *TradingBot) bookCancelledFill(pos *TradingPosition, order TrancheOrder, cancelled *OrderResponse) {
+	executedQty, _ := strconv.ParseFloat(cancelled.ExecutedQty, 64)
+	if executedQty = min(executedQty, pos.Quantity); executedQty <= 0 {
+		return
+	}
+	*pos = bot.bookTrancheFill(*pos, order, cancelled, executedQty)
+}
+
+// waitForSettledBalance polls the free balance of a bought asset with exponential backoff until it covers
+// the quantity, giving up after SellPlaceTimeout
+func (bot *TradingBot) waitForSettledBalance(asset string, quantity float64) bool {
+	if bot.DryRun {
+		return true // Simulated fills settle immediately
+	}
+
+	logInfo.Printf("   [BINANCE MAINNET] Waiting up to %s for %.8f %s to settle...\n", bot.SellPlaceTimeout, quantity, asset)
+	deadline := time.Now().Add(bot.SellPlaceTimeout)
+
+	for attempt := 1; ; attempt++ {
+		free, err := bot.Binance.AccountBalance(asset)
+		if err != nil {
+			logWarn.Printf("   WARNING: Could not check %s balance: %v\n", asset, err)
+		} else if free >= quantity {
+			return true
+		} else {
+			logInfo.Printf("   %s free balance %.8f is below bought quantity %.8f\n", asset, free, quantity)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		delay := min(backoffDelay(bot.SellRetryBackoff, attempt), remaining)
+		logInfo.Printf("   Checking balance again in %s...\n", delay)
+		time.Sleep(delay)
+	}
+}
+
+// backoffDelay returns the exponential backoff delay for a 1-based attempt number
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	return base * time.Duration(1<<(attempt-1))
+}
+
+// reconcileSellOrders checks resting sell orders on Binance and closes positions whose order filled
+func (bot *TradingBot) reconcileSellOrders() {
+	positions := bot.getPositionsSnapshot()
+	if len(positions) == 0 {
+		return
+	}
+
+	logInfo.Println("\n=== Reconciling Sell Orders ===")
+
+	remaining := make([]TradingPosition, 0, len(positions))
+	for _, pos := range positions {
+		if !pos.HasActiveSellOrder {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		if len(pos.Tranches) > 0 {
+			if open, ok := bot.reconcileTranches(pos); ok {
+				remaining = append(remaining, open)
+			}
+			continue
+		}
+
+		bot.throttleRequestWeight()
+		coinName := strings.TrimSuffix(pos.Symbol, "USDT")
+		order, err := bot.queryOrder(pos.Symbol, pos.SellOrderID)
+		if err != nil {
+			logWarn.Printf("WARNING: Could not check sell order %d for %s: %v\n", pos.SellOrderID, coinName, err)
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		// When an OCO's stop triggers, its target leg expires; the stop leg then holds the fill
+		if pos.StopOrderID != 0 && (order.Status == StatusExpired || order.Status == StatusCanceled) {
+			stopOrder, err := bot.queryOrder(pos.Symbol, pos.StopOrderID)
+			if err != nil {
+				logWarn.Printf("WARNING: Could not check OCO stop order %d for %s: %v\n", pos.StopOrderID, coinName, err)
+				remaining = append(remaining, pos)
+				continue
+			}
+			if stopOrder.Status == StatusFilled {
+				executedQty, _ := strconv.ParseFloat(stopOrder.ExecutedQty, 64)
+				quoteQty, _ := strconv.ParseFloat(stopOrder.QuoteQty, 64)
+				sellPrice, _ := strconv.ParseFloat(stopOrder.Price, 64)
+				if executedQty > 0 && quoteQty > 0 {
+					sellPrice = quoteQty / executedQty
+				}
+
+				// A triggered stop-limit usually takes liquidity, so estimate the taker fee
+				sellFee := sellPrice * pos.Quantity * bot.applyBNBDiscount(bot.TakerFeePercent) / 100
+				if bot.BNBFeeDiscount {
+					if bnbPrice, err := bot.bnbPrice(); err == nil {
+						pos.BNBFeesPaid += sellFee / bnbPrice
+					}
+				}
+
+				trade := bot.recordCompletedTrade(pos, sellPrice, sellFee)
+				logInfo.Printf("STOPPED OUT: %s OCO stop order %d filled at $%.6f (P/L: %.2f USDT, %.2f%%, held %s)\n",
+					coinName, pos.StopOrderID, trade.SellPrice, trade.Profit, trade.ProfitPercent,
+					trade.HoldDuration.Round(time.Minute))
+				continue
+			}
+			if stopOrder.Status == StatusNew || stopOrder.Status == StatusPartiallyFilled {
+				logInfo.Printf("OPEN: %s OCO stop order %d is %s\n", coinName, pos.StopOrderID, stopOrder.Status)
+				remaining = append(remaining, pos)
+				continue
+			}
+		}
+
+		switch order.Status {
+		case StatusFilled:
+			executedQty, _ := strconv.ParseFloat(order.ExecutedQty, 64)
+			quoteQty, _ := strconv.ParseFloat(order.QuoteQty, 64)
+			sellPrice := pos.TargetSellPrice
+			if executedQty > 0 && quoteQty > 0 {
+				sellPrice = quoteQty / executedQty
+			}
+
+			// Order queries don't include commissions, so estimate the maker fee in USDT terms
+			sellFee := sellPrice * pos.Quantity * bot.sellFeePercent() / 100
+			if bot.BNBFeeDiscount {
+				if bnbPrice, err := bot.bnbPrice(); err == nil {
+					pos.BNBFeesPaid += sellFee / bnbPrice
+				}
+			}
+
+			trade := bot.recordCompletedTrade(pos, sellPrice, sellFee)
+			logInfo.Printf("SOLD: %s sell order %d filled at $%.6f (P/L: %.2f USDT, %.2f%%, held %s)\n",
+				coinName, pos.SellOrderID, trade.SellPrice, trade.Profit, trade.ProfitPercent,
+				trade.HoldDuration.Round(time.Minute))
+		case StatusCanceled, StatusExpired, StatusRejected:
+			logWarn.Printf("WARNING: Sell order %d for %s is %s - position is now monitored without a resting order\n",
+				pos.SellOrderID, coinName, order.Status)
+			pos.HasActiveSellOrder = false
+			pos.SellOrderID = 0
+			pos.StopOrderID = 0
+			pos.OCOOrderListID = 0
+			remaining = append(remaining, pos)
+		default:
+			logInfo.Printf("OPEN: %s sell order %d is %s (target $%.6f)\n",
+				coinName, pos.SellOrderID, order.Status, pos.TargetSellPrice)
+			remaining = append(remaining, pos)
+		}
+	}
+
+	bot.setPositions(remaining)
+	bot.saveState()
+
+	// Stop-loss and value updates rely on a price for every open position, not just watchlist coins
+	bot.fetchPositionPrices(remaining)
+}
+
+// reconcileTranches checks each sell tranche of a scaled-out position, booking every filled tranche as its own
+// completed trade. A tranche cancelled or expired part-way has its fill booked and the unsold rest re-placed at
+// the same price. It returns the rest of the position, or false once all of it has sold.
+func (bot *TradingBot) reconcileTranches(pos TradingPosition) (TradingPosition, bool) {
+	coinName := strings.TrimSuffix(pos.Symbol, "USDT")
+
+	open := make([]TrancheOrder, 0, len(pos.Tranches))
+	unplaced := false
+	for _, tranche := range pos.Tranches {
+		bot.throttleRequestWeight()
+		order, err := bot.queryOrder(pos.Symbol, tranche.OrderID)
+		if err != nil {
+			logWarn.Printf("WARNING: Could not check sell tranche %d for %s: %v\n", tranche.OrderID, coinName, err)
+			open = append(open, tranche)
+			continue
+		}
+
+		switch order.Status {
+		case StatusFilled:
+			pos = bot.bookTrancheFill(pos, tranche, order, tranche.Quantity)
+		case StatusCanceled, StatusExpired, StatusRejected:
+			executedQty, _ := strconv.ParseFloat(order.ExecutedQty, 64)
+			executedQty = min(executedQty, tranche.Quantity)
+			if executedQty > 0 {
+				pos = bot.bookTrancheFill(pos, tranche, order, executedQty)
+			}
+
+			unsold := tranche.Quantity - executedQty
+			if unsold <= tranche.Quantity*1e-6 {
+				continue
+			}
+			if replaced, ok := bot.replaceTranche(pos.Symbol, tranche, unsold); ok {
+				logWarn.Printf("WARNING: Sell tranche %d for %s is %s - re-placed its unsold %.8f %s as order %d\n",
+					tranche.OrderID, coinName, order.Status, replaced.Quantity, coinName, replaced.OrderID)
+				open = append(open, replaced)
+			} else {
+				logWarn.Printf("WARNING: Sell tranche %d for %s is %s and its unsold %.8f %s could not be re-placed\n",
+					tranche.OrderID, coinName, order.Status, unsold, coinName)
+				unplaced = true
+			}
+		default:
+			logInfo.Printf("OPEN: %s sell tranche %d is %s (%.8f at $%.6f)\n",
+				coinName, tranche.OrderID, order.Status, tranche.Quantity, tranche.Price)
+			open = append(open, tranche)
+		}
+	}
+
+	if pos.Quantity == 0 {
+		return pos, false
+	}
+
+	pos.Tranches = open
+
+	// Quantity without an order never sells while the open tranches keep the position marked active,
+	// so release them and monitor the whole rest without a resting order
+	if unplaced && len(open) > 0 {
+		if err := bot.cancelSellOrders(&pos); err != nil {
+			logWarn.Printf("WARNING: %v\n", err)
+		} else {
+			logWarn.Printf("WARNING: Cancelled the other %s sell tranches - position is now monitored without a resting order\n", coinName)
+		}
+		if pos.Quantity == 0 {
+			return pos, false
+		}
+	}
+
+	if len(pos.Tranches) == 0 {
+		pos.Tranches = nil
+		pos.HasActiveSellOrder = false
+		pos.SellOrderID = 0
+	} else {
+		pos.SellOrderID = pos.Tranches[0].OrderID
+	}
+	return pos, true
+}
+
+// bookTrancheFill records quantity sold by a sell tranche (or any limit sell) as a completed trade and returns the rest of the position
+func (bot *TradingBot) bookTrancheFill(pos TradingPosition, tranche TrancheOrder, order *OrderResponse, quantity float64) TradingPosition {
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQty, 64)
+	quoteQty, _ := strconv.ParseFloat(order.QuoteQty, 64)
+	sellPrice := tranche.Price
+	if executedQty > 0 && quoteQty > 0 {
+		sellPrice = quoteQty / executedQty
+	}
+
+	sold, rest := splitPosition(pos, quantity)
+
+	// Order queries don't include commissions, so estimate the maker fee in USDT terms
+	sellFee := sellPrice * sold.Quantity * bot.sellFeePercent() / 100
+	if bot.BNBFeeDiscount {
+		if bnbPrice, err := bot.bnbPrice(); err == nil {
+			sold.BNBFeesPaid += sellFee / bnbPrice
+		}
+	}
+
+	trade := bot.recordCompletedTrade(sold, sellPrice, sellFee)
+	logInfo.Printf("SOLD: %s sell order %d filled %.8f at $%.6f (P/L: %.2f USDT, %.2f%%, held %s)\n",
+		strings.TrimSuffix(pos.Symbol, "USDT"), tranche.OrderID, trade.Quantity, trade.SellPrice, trade.Profit,
+		trade.ProfitPercent, trade.HoldDuration.Round(time.Minute))
+
+	if rest.Quantity <= quantity*1e-6 {
+		rest.Quantity = 0 // Float noise left over from the last tranche
+	}
+	return rest
+}
+
+// replaceTranche places a new limit sell at a tranche's price for the quantity its order left unsold
+func (bot *TradingBot) replaceTranche(symbol string, tranche TrancheOrder, quantity float64) (TrancheOrder, bool) {
+	filters, err := bot.getSymbolFilters(symbol)
+	if err != nil {
+		logWarn.Printf("WARNING: Could not get symbol filters to re-place a sell tranche for %s: %v\n", symbol, err)
+		return TrancheOrder{}, false
+	}
+
+	minNotional, _ := strconv.ParseFloat(filters.MinNotional, 64)
+	quantity = roundToStepSize(quantity, filters.StepSize)
+	if quantity <= 0 || quantity*tranche.Price < minNotional {
+		return TrancheOrder{}, false
+	}
+
+	bot.throttleRequestWeight()
+	orderResp, err := bot.executeLimitSellOrder(symbol, quantity, tranche.Price)
+	if err != nil {
+		logWarn.Printf("WARNING: Failed to re-place sell tranche for %s (%.8f at $%.6f): %v\n", symbol, quantity, tranche.Price, err)
+		return TrancheOrder{}, false
+	}
+	return TrancheOrder{OrderID: orderResp.OrderID, Price: tranche.Price, Quantity: quantity}, true
+}
+
+// fetchPositionPrices fetches the ticker price of every position whose symbol is not in the watchlist
+func (bot *TradingBot) fetchPositionPrices(positions []TradingPosition) {
+	watched := make(map[string]bool, len(bot.WatchList))
+	for _, coin := range bot.WatchList {
+		watched[coin.Symbol] = true
+	}
+
+	for _, pos := range positions {
+		if watched[pos.Symbol] {
+			continue
+		}
+		if _, ok := bot.positionPrices[pos.Symbol]; ok {
+			continue
+		}
+
+		bot.throttleRequestWeight()
+		price, err := bot.getTickerPrice(pos.Symbol)
+		if err != nil {
+			log.Printf("WARNING: Could not fetch price for %s outside the watchlist: %v", pos.Symbol, err)
+			continue
+		}
+		if bot.positionPrices == nil {
+			bot.positionPrices = make(map[string]float64)
+		}
+		bot.positionPrices[pos.Symbol] = price
+	}
+}
+
+// checkExitTargets market-sells positions that reached their target when running in market exit mode
+func (bot *TradingBot) checkExitTargets() {
+	positions := bot.getPositionsSnapshot()
+	if bot.ExitOrderType != exitOrderMarket || len(positions) == 0 {
+		return
+	}
+
+	logInfo.Println("\n=== Checking Exit Targets (market exit mode) ===")
+
+	prices := bot.currentPrices()
+
+	remaining := make([]TradingPosition, 0, len(positions))
+	for _, pos := range positions {
+		coinName := strings.TrimSuffix(pos.Symbol, "USDT")
+		currentPrice, ok := prices[pos.Symbol]
+		if !ok || pos.HasActiveSellOrder || currentPrice < pos.TargetSellPrice {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		logInfo.Printf("TARGET HIT: %s at $%.6f (target $%.6f) - placing market sell\n",
+			coinName, currentPrice, pos.TargetSellPrice)
+
+		pos.Quantity = bot.sellQuantity(pos.Symbol, pos.Quantity)
+		orderResp, err := bot.executeSellOrder(pos.Symbol, pos.Quantity)
+		if err != nil {
+			logError.Printf("   ERROR: Market sell failed: %v\n", err)
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		_, sellFee, bnbFee := fillCommissions(orderResp.Fills, coinName)
+		pos.BNBFeesPaid += bnbFee
+		trade := bot.recordCompletedTrade(pos, averageFillPrice(orderResp, currentPrice), sellFee+bot.bnbFeeValue(bnbFee))
+		logInfo.Printf("   [BINANCE MAINNET] SUCCESS: Sold %.6f %s at $%.6f (P/L: %.2f USDT, %.2f%%)\n",
+			trade.Quantity, coinName, trade.SellPrice, trade.Profit, trade.ProfitPercent)
+	}
+
+	if len(remaining) != len(positions) {
+		bot.setPositions(remaining)
+		bot.saveState()
+	}
+}
+
+// checkStopLosses market-sells positions whose price fell through their stop: StopLossPercent below
+// the buy price, or TrailingStopPercent below the highest price seen since buying
+func (bot *TradingBot) checkStopLosses() {
+	positions := bot.getPositionsSnapshot()
+	if (bot.StopLossPercent <= 0 && bot.TrailingStopPercent <= 0) || len(positions) == 0 {
+		return
+	}
+
+	if bot.TrailingStopPercent > 0 {
+		logInfo.Printf("\n=== Checking Trailing Stops (-%.1f%% from high) ===\n", bot.TrailingStopPercent)
+	} else {
+		logInfo.Printf("\n=== Checking Stop-Losses (-%.1f%%) ===\n", bot.StopLossPercent)
+	}
+
+	prices := bot.currentPrices()
+
+	remaining := make([]TradingPosition, 0, len(positions))
+	positionsChanged := false
+	for _, pos := range positions {
+		coinName := strings.TrimSuffix(pos.Symbol, "USDT")
+		currentPrice, ok := prices[pos.Symbol]
+		if !ok {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		// Positions restored from older state files have no recorded high yet
+		if pos.HighestPrice < pos.BuyPrice {
+			pos.HighestPrice = pos.BuyPrice
+			positionsChanged = true
+		}
+		if currentPrice > pos.HighestPrice {
+			pos.HighestPrice = currentPrice
+			positionsChanged = true
+		}
+
+		// Binance triggers the stop leg of an OCO sell itself
+		if pos.StopOrderID != 0 {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		stopPrice := pos.BuyPrice * (1 - bot.StopLossPercent/100)
+		if bot.TrailingStopPercent > 0 {
+			stopPrice = pos.HighestPrice * (1 - bot.TrailingStopPercent/100)
+		}
+		if currentPrice > stopPrice {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		logInfo.Printf("STOP-LOSS: %s at $%.6f (stop $%.6f, bought $%.6f, high $%.6f)\n",
+			coinName, currentPrice, stopPrice, pos.BuyPrice, pos.HighestPrice)
+
+		// The resting limit sell holds the quantity, so it must be cancelled first
+		if pos.HasActiveSellOrder {
+			positionsChanged = true
+			if err := bot.cancelSellOrders(&pos); err != nil {
+				logError.Printf("   ERROR: %v\n", err)
+				remaining = append(remaining, pos)
+				continue
+			}
+			logInfo.Printf("   Cancelled limit sell order for %s\n", coinName)
+			if pos.Quantity == 0 {
+				continue // The sell filled completely before the cancel
+			}
+		}
+
+		pos.Quantity = bot.sellQuantity(pos.Symbol, pos.Quantity)
+		orderResp, err := bot.executeSellOrder(pos.Symbol, pos.Quantity)
+		if err != nil {
+			logError.Printf("   ERROR: Stop-loss market sell failed: %v\n", err)
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		_, sellFee, bnbFee := fillCommissions(orderResp.Fills, coinName)
+		pos.BNBFeesPaid += bnbFee
+		trade := bot.recordCompletedTrade(pos, averageFillPrice(orderResp, currentPrice), sellFee+bot.bnbFeeValue(bnbFee))
+		logInfo.Printf("   [BINANCE MAINNET] Stop-loss executed: Sold %.6f %s at $%.6f (P/L: %.2f USDT, %.2f%%)\n",
+			trade.Quantity, coinName, trade.SellPrice, trade.Profit, trade.ProfitPercent)
+	}
+
+	if positionsChanged || len(remaining) != len(positions) {
+		bot.setPositions(remaining)
+		bot.saveState()
+	}
+}
+
+// checkMaxHold market-sells positions held longer than MaxHold, cancelling their resting sell first.
+// The sale is recorded as a completed trade whether or not it made a profit.
+func (bot *TradingBot) checkMaxHold() {
+	positions := bot.getPositionsSnapshot()
+	if bot.MaxHold <= 0 || len(positions) == 0 {
+		return
+	}
+
+	logInfo.Printf("\n=== Checking Position Age (max hold %s) ===\n", bot.MaxHold)
+
+	prices := bot.currentPrices()
+
+	remaining := make([]TradingPosition, 0, len(positions))
+	changed := false
+	for _, pos := range positions {
+		held := time.Since(pos.BuyTime)
+		if held <= bot.MaxHold {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		coinName := strings.TrimSuffix(pos.Symbol, "USDT")
+		logInfo.Printf("MAX HOLD: %s held %s (limit %s), forcing a market exit\n",
+			coinName, held.Round(time.Minute), bot.MaxHold)
+		changed = true // Even a failed exit may have cancelled the resting sell
+
+		// The resting sell holds the quantity; whatever it already sold is booked, so only the rest is sold here
+		if pos.HasActiveSellOrder {
+			if err := bot.cancelSellOrders(&pos); err != nil {
+				logError.Printf("   ERROR: %v\n", err)
+				remaining = append(remaining, pos)
+				continue
+			}
+			logInfo.Printf("   Cancelled sell order for %s\n", coinName)
+			if pos.Quantity == 0 {
+				continue // The sell filled completely before the cancel
+			}
+		}
+
+		fallbackPrice := pos.BuyPrice
+		if price, ok := prices[pos.Symbol]; ok {
+			fallbackPrice = price
+		}
+
+		pos.Quantity = bot.sellQuantity(pos.Symbol, pos.Quantity)
+		orderResp, err := bot.executeSellOrder(pos.Symbol, pos.Quantity)
+		if err != nil {
+			logError.Printf("   ERROR: Forced exit market sell failed: %v\n", err)
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		_, sellFee, bnbFee := fillCommissions(orderResp.Fills, coinName)
+		pos.BNBFeesPaid += bnbFee
+		trade := bot.recordCompletedTrade(pos, averageFillPrice(orderResp, fallbackPrice), sellFee+bot.bnbFeeValue(bnbFee))
+		logInfo.Printf("   Forced exit: Sold %.6f %s at $%.6f after %s (P/L: %.2f USDT, %.2f%%)\n",
+			trade.Quantity, coinName, trade.SellPrice, trade.HoldDuration.Round(time.Minute), trade.Profit, trade.ProfitPercent)
+		logEvent(slog.LevelInfo, "max_hold_exit", "symbol", pos.Symbol, "heldHours", held.Hours(),
+			"sellPrice", trade.SellPrice, "profit", trade.Profit, "profitPercent", trade.ProfitPercent)
+	}
+
+	if changed {
+		bot.setPositions(remaining)
+		bot.saveState()
+	}
+}
+
+// updatePositionValues refreshes each position's CurrentValue from the latest watchlist prices
+func (bot *TradingBot) updatePositionValues() {
+	prices := bot.currentPrices()
+
+	bot.stateMu.Lock()
+	count := len(bot.Positions)
+	value, invested := 0.0, 0.0
+	for i := range bot.Positions {
+		pos := &bot.Positions[i]
+		if price, ok := prices[pos.Symbol]; ok {
+			pos.CurrentValue = pos.Quantity * price
+		}
+		value += pos.CurrentValue
+		invested += pos.InvestedAmount
+	}
+	bot.stateMu.Unlock()
+
+	if count == 0 {
+		return
+	}
+	logInfo.Printf("Open positions: %d | Value: %.2f USDT | Unrealized P/L: %+.2f USDT\n",
+		count, value, value-invested)
+}
+
+// currentPrices maps each watchlist symbol, and each open position outside it, to its latest price
+func (bot *TradingBot) currentPrices() map[string]float64 {
+	prices := make(map[string]float64, len(bot.WatchList)+len(bot.positionPrices))
+	for symbol, price := range bot.positionPrices {
+		prices[symbol] = price
+	}
+	for _, coin := range bot.WatchList {
+		prices[coin.Symbol] = coin.LastPrice
+	}
+	return prices
+}
+
+// netProfitAt returns the profit a position would make if fully sold at price, after buy and sell fees
+func (bot *TradingBot) netProfitAt(pos TradingPosition, price float64) float64 {
+	proceeds := price * pos.Quantity * (1 - bot.sellFeePercent()/100)
+	return proceeds - pos.InvestedAmount - pos.FeesPaid
+}
+
+// recordCompletedTrade records a sold position and returns its proceeds to the available budget.
+// sellFees is the quote-asset commission charged on the sell; BNB commissions are tracked separately.
+func (bot *TradingBot) recordCompletedTrade(pos TradingPosition, sellPrice float64, sellFees float64) CompletedTrade {
+	sellTime := time.Now()
+	proceeds := sellPrice*pos.Quantity - sellFees
+	profit := proceeds - pos.InvestedAmount - pos.FeesPaid
+
+	profitPercent := 0.0
+	if pos.InvestedAmount > 0 {
+		profitPercent = profit / pos.InvestedAmount * 100
+	}
+
+	trade := CompletedTrade{
+		ID:             pos.ID,
+		Symbol:         pos.Symbol,
+		BuyPrice:       pos.BuyPrice,
+		SellPrice:      sellPrice,
+		Quantity:       pos.Quantity,
+		InvestedAmount: pos.InvestedAmount,
+		Fees:           pos.FeesPaid + pos.BaseFeesPaid*pos.BuyPrice + sellFees, // Base fee already shrank Quantity
+		Profit:         profit,
+		ProfitPercent:  profitPercent,
+		BuyTime:        pos.BuyTime,
+		SellTime:       sellTime,
+		HoldDuration:   sellTime.Sub(pos.BuyTime),
+	}
+
+	bot.stateMu.Lock()
+	bot.CompletedTrades = append(bot.CompletedTrades, trade)
+	bot.AvailableBudget += proceeds
+	bot.updateStats(trade)
+	bot.stateMu.Unlock()
+
+	bot.skimProfit(trade)
+	bot.notify(sellMessage(trade))
+	logEvent(slog.LevelInfo, "sell", "symbol", trade.Symbol, "price", trade.SellPrice, "quantity", trade.Quantity,
+		"buyPrice", trade.BuyPrice, "profit", trade.Profit, "profitPercent", trade.ProfitPercent, "fees", trade.Fees)
+
+	return trade
+}
+
+// updateStats folds a completed trade into the performance statistics. The caller must hold stateMu.
+func (bot *TradingBot) updateStats(trade CompletedTrade) {
+	stats := &bot.Stats
+
+	stats.TotalTrades++
+	stats.TotalFees += trade.Fees
+	if trade.Profit > 0 {
+		stats.WinningTrades++
+		stats.TotalProfit += trade.Profit
+		stats.LargestWin = math.Max(stats.LargestWin, trade.Profit)
+	} else {
+		stats.LosingTrades++
+		stats.TotalLoss += -trade.Profit
+		stats.LargestLoss = math.Max(stats.LargestLoss, -trade.Profit)
+	}
+
+	stats.NetProfit = stats.TotalProfit - stats.TotalLoss
+	stats.WinRate = float64(stats.WinningTrades) / float64(stats.TotalTrades) * 100
+	if stats.WinningTrades > 0 {
+		stats.AverageProfit = stats.TotalProfit / float64(stats.WinningTrades)
+	}
+	if stats.LosingTrades > 0 {
+		stats.AverageLoss = stats.TotalLoss / float64(stats.LosingTrades)
+	}
+
+	// Running average of hold time
+	n := time.Duration(stats.TotalTrades)
+	stats.AverageHoldTime = (stats.AverageHoldTime*(n-1) + trade.HoldDuration) / n
+}
+
+// printStats prints a summary of trading performance
+func (bot *TradingBot) printStats() {
+	stats := bot.getStatsSnapshot()
+
+	logInfo.Printf("\n=== PERFORMANCE ===\n")
+	if stats.TotalTrades == 0 {
+		logInfo.Printf("No completed trades yet | Open positions: %d\n", len(bot.getPositionsSnapshot()))
+		return
+	}
+
+	logInfo.Printf("Trades: %d (%d won / %d lost) | Win rate: %.1f%%\n",
+		stats.TotalTrades, stats.WinningTrades, stats.LosingTrades, stats.WinRate)
+	logInfo.Printf("Net profit: %+.2f USDT (won %.2f / lost %.2f)\n", stats.NetProfit, stats.TotalProfit, stats.TotalLoss)
+	logInfo.Printf("Average win: %.2f USDT | Average loss: %.2f USDT\n", stats.AverageProfit, stats.AverageLoss)
+	logInfo.Printf("Largest win: %.2f USDT | Largest loss: %.2f USDT\n", stats.LargestWin, stats.LargestLoss)
+	logInfo.Printf("Average hold time: %s\n", stats.AverageHoldTime.Round(time.Minute))
+	logInfo.Printf("Fees paid: %.4f USDT (included in net profit)\n", stats.TotalFees)
+	if stats.BankedProfit > 0 {
+		logInfo.Printf("Banked profit: %.2f USDT\n", stats.BankedProfit)
+	}
+}
+
+// skimProfit banks a fraction of a profitable trade's gain so it is no longer traded with
+func (bot *TradingBot) skimProfit(trade CompletedTrade) {
+	if bot.ProfitSkimPercent <= 0 || trade.Profit <= 0 {
+		return
+	}
+
+	skim := trade.Profit * bot.ProfitSkimPercent / 100
+	bot.stateMu.Lock()
+	bot.AvailableBudget -= skim
+	bot.Stats.BankedProfit += skim
+	banked := bot.Stats.BankedProfit
+	bot.stateMu.Unlock()
+
+	logInfo.Printf("   [PROFIT SKIM] Banked %.2f USDT (%.0f%% of %.2f profit) - total banked: %.2f USDT\n",
+		skim, bot.ProfitSkimPercent, trade.Profit, banked)
+
+	if bot.ProfitSkimEmail == "" {
+		return
+	}
+
+	if err := bot.transferToSubAccount(bot.ProfitSkimEmail, "USDT", skim); err != nil {
+		logWarn.Printf("   WARNING: Banked profit transfer to %s failed (kept logically only): %v\n", bot.ProfitSkimEmail, err)
+		return
+	}
+	logInfo.Printf("   [PROFIT SKIM] Transferred %.2f USDT to sub-account %s\n", skim, bot.ProfitSkimEmail)
+}
+
+// transferToSubAccount moves an asset from the master spot wallet to a sub-account spot wallet
+func (bot *TradingBot) transferToSubAccount(email, asset string, amount float64) error {
+	if bot.DryRun {
+		return fmt.Errorf("transfers are disabled in dry run mode")
+	}
+
+	return bot.Binance.TransferToSubAccount(email, asset, amount)
+}
+
+// getCurrentPortfolioValue calculates the current value of all positions
+func (bot *TradingBot) getCurrentPortfolioValue() float64 {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	totalValue := 0.0
+	for _, pos := range bot.Positions {
+		totalValue += pos.CurrentValue
+	}
+	return totalValue
+}
+
+// realizedPnL returns the profit booked across all completed trades, after fees
+func (bot *TradingBot) realizedPnL() float64 {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	total := 0.0
+	for _, trade := range bot.CompletedTrades {
+		total += trade.Profit
+	}
+	return total
+}
+
+// unrealizedPnL returns the paper profit of the open positions at their last known value
+func (bot *TradingBot) unrealizedPnL() float64 {
+	bot.stateMu.RLock()
+	defer bot.stateMu.RUnlock()
+
+	total := 0.0
+	for _, pos := range bot.Positions {
+		total += pos.CurrentValue - pos.InvestedAmount
+	}
+	return total
+}
+
+// runTradingCycle executes one complete trading cycle with optimized CMC+Binance integration
+func (bot *TradingBot) runTradingCycle() error {
+	start := time.Now()
+	defer func() { bot.LastCycleDuration = time.Since(start) }()
+
+	logInfo.Print("\n" + strings.Repeat("=", 80))
+	logInfo.Printf("\nOptimized Trading Bot Cycle - %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	logInfo.Printf("Data Source: %s\n", bot.dataSourceLabel())
+	logInfo.Printf("Trading Platform: Binance (buy/sell execution only)\n")
+	logInfo.Printf("Strategy: Buy %s drops, Sell at +%.1f%% net profit\n", bot.dropBandLabel(), bot.ProfitTargetPercent)
+	logInfo.Print(strings.Repeat("=", 80))
+
+	// Sit out Binance maintenance instead of failing every order
+	if !bot.maintenanceOver() {
+		return ErrTradingPaused
+	}
+
+	// Resync the clock offset so long-running bots don't drift outside the recvWindow
+	if !bot.DryRun {
+		if err := syncServerTime(bot.httpClient, bot.Binance.Config.BaseURL); err != nil {
+			log.Printf("WARNING: Could not sync with Binance server time: %v", err)
+		}
+	}
+
+	// BNB fees are valued at this cycle's BNB price
+	bot.bnbPriceMu.Lock()
+	bot.bnbPriceCache = 0
+	bot.bnbPriceMu.Unlock()
+	bot.positionPrices = make(map[string]float64)
+
+	// Close positions whose resting sell order filled since the last cycle
+	bot.reconcileSellOrders()
+
+	// One exchangeInfo request per cycle instead of one per order
+	bot.throttleRequestWeight()
+	if err := bot.prefetchExchangeInfo(); err != nil {
+		log.Printf("WARNING: Failed to prefetch exchange info: %v", err)
+	}
+	bot.throttleRequestWeight()
+
+	// Fetch current market data for the top coins from the configured data source
+	watchList, err := bot.fetchWatchList()
+	if err != nil {
+		return fmt.Errorf("failed to fetch watchlist: %w", err)
+	}
+
+	bot.WatchList = watchList
+	logInfo.Printf("\nMonitoring %d non-stablecoin coins\n", len(bot.WatchList))
+
+	// Positions that dropped out of the fresh watchlist still need a price
+	bot.fetchPositionPrices(bot.getPositionsSnapshot())
+
+	// Mark open positions to market so portfolio value and unrealized P/L are current
+	bot.updatePositionValues()
+
+	// Market exit mode: sell positions whose target has been reached
+	bot.checkExitTargets()
+
+	// Cut losses on positions that kept falling
+	bot.checkStopLosses()
+
+	// Free capital tied up in positions held past MAX_HOLD_HOURS
+	bot.checkMaxHold()
+
+	// Analyze new buy opportunities using CMC data
+	opportunities, bought := bot.analyzeTradingOpportunities()
+
+	// One scannable heartbeat line per cycle, for when the per-coin output is too verbose to follow
+	logInfo.Printf("CYCLE SUMMARY: scanned %d | opportunities %d | bought %d | open positions %d | budget %.2f USDT | took %s\n",
+		len(bot.WatchList), opportunities, bought, len(bot.getPositionsSnapshot()), bot.getAvailableBudget(),
+		time.Since(start).Round(time.Millisecond))
+
+	// Record volumes after analysis so a spike is compared against previous cycles only
+	bot.recordVolumes()
+
+	// Persist state so restarts and the status command see the latest positions
+	bot.saveState()
+
+	bot.printStats()
+
+	logEvent(slog.LevelInfo, "cycle_complete", "openPositions", len(bot.getPositionsSnapshot()),
+		"availableBudget", bot.getAvailableBudget(), "watchlist", len(bot.WatchList))
+
+	return nil
+}
+
+// runCycle runs one trading cycle, reporting a failure to the log and alert channels
+func (bot *TradingBot) runCycle() error {
+	err := bot.runTradingCycle()
+
+	// A maintenance pause was already reported when it started; it only needs recording for health
+	if err != nil && !errors.Is(err, ErrTradingPaused) {
+		log.Printf("Error in trading cycle: %v", err)
+		logEvent(slog.LevelError, "cycle_error", "error", err.Error())
+		bot.notifyDiscord(fmt.Sprintf("Trading cycle failed: %v", err))
+	}
+	bot.recordCycleResult(err)
+	return err
+}
+
+// recordCycleResult stores the outcome of a trading cycle for the health endpoint
+func (bot *TradingBot) recordCycleResult(err error) {
+	status := bot.snapshotStatus()
+
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+
+	bot.CyclesRun++
+	if err != nil {
+		bot.LastCycleError = err.Error()
+		bot.CycleErrors++
+	} else {
+		bot.LastCycleTime = time.Now()
+		bot.LastCycleError = ""
+	}
+
+	status.LastCycleTime = bot.LastCycleTime
+	status.LastCycleError = bot.LastCycleError
+	status.CyclesRun = bot.CyclesRun
+	status.CycleErrors = bot.CycleErrors
+	bot.status = status
+}
+
+// cycleInterval returns the configured trading cycle interval, falling back to the default when invalid
+func cycleInterval() time.Duration {
+	defaultMinutes := int(defaultCycleInterval / time.Minute)
+	minutes := getEnvInt("CYCLE_INTERVAL_MINUTES", defaultMinutes)
+	if minutes <= 0 || minutes > maxCycleMinutes {
+		log.Printf("WARNING: CYCLE_INTERVAL_MINUTES must be between 1 and %d, using default %d", maxCycleMinutes, defaultMinutes)
+		minutes = defaultMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// confirmLiveTrading shows the balance and asks the user to type CONFIRM before live trading starts.
+// Anything else, including a closed stdin under cron or systemd, aborts the start.
+func (bot *TradingBot) confirmLiveTrading(in io.Reader) error {
+	fmt.Println()
+	fmt.Println("=== LIVE TRADING WITH REAL MONEY ===")
+	fmt.Printf("Total budget: %.2f USDT | Available: %.2f USDT | Open positions: %d\n",
+		bot.TotalBudget, bot.getAvailableBudget(), len(bot.getPositionsSnapshot()))
+	fmt.Print("Check the settings above, then type CONFIRM to start trading: ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("no confirmation received (%v) - run start --yes or set AUTO_CONFIRM=true to start without the prompt", err)
+	}
+	if strings.TrimSpace(answer) != "CONFIRM" {
+		return fmt.Errorf("live trading not confirmed, exiting")
+	}
+
+	fmt.Println("Confirmed, starting live trading")
+	return nil
+}
+
+// startBot starts the trading bot, running a cycle every CYCLE_INTERVAL_MINUTES
+func (bot *TradingBot) startBot() {
+	interval := cycleInterval()
+
+	// Keep the default health window at 2x the actual cycle interval
+	if os.Getenv("HEALTH_STALE_MINUTES") == "" {
+		bot.HealthStaleAfter = 2 * interval
+	}
+
+	logInfo.Println("Starting Trading Bot...")
+	if bot.DryRun {
+		logInfo.Println("MODE: DRY RUN - orders are simulated, nothing is sent to Binance")
+		if bot.SimulatedSlippage > 0 {
+			logInfo.Printf("Simulated slippage: %.2f%% against each market fill\n", bot.SimulatedSlippage)
+		}
+	}
+	logInfo.Printf("Strategy: Buy on drops between %.1f%% to %.1f%% (safety limit %.1f%%) | Sell at +%.1f%% net profit\n",
+		bot.BuyDropMin, bot.BuyDropMax, bot.SafetyDropLimit, bot.ProfitTargetPercent)
+	logInfo.Printf("Budget: %.2f USDT | Investment per trade: %s\n", bot.TotalBudget, bot.investmentLabel())
+	logInfo.Printf("Cycle frequency: Every %s\n", interval)
+	if bot.TrailingStopPercent > 0 {
+		logInfo.Printf("Trailing stop: market-sell %.1f%% below the high since buying\n", bot.TrailingStopPercent)
+	} else if bot.StopLossPercent > 0 {
+		logInfo.Printf("Stop-loss: market-sell %.1f%% below the buy price\n", bot.StopLossPercent)
+	}
+	if bot.MaxTradeUSDT > 0 || bot.MaxTotalExposure > 0 {
+		logInfo.Printf("Risk caps: %.2f USDT per trade | %.2f USDT total exposure (0 = no cap)\n",
+			bot.MaxTradeUSDT, bot.MaxTotalExposure)
+	}
+	if bot.MaxDailyLoss > 0 {
+		logInfo.Printf("Daily loss limit: no new buys after losing %.2f USDT in a day\n", bot.MaxDailyLoss)
+	}
+	if bot.MaxHold > 0 {
+		logInfo.Printf("Max hold: market-sell positions still open after %s\n", bot.MaxHold)
+	}
+	if len(bot.SymbolBlacklist) > 0 {
+		logInfo.Printf("Blacklist: never buying %s\n", strings.Join(slices.Sorted(maps.Keys(bot.SymbolBlacklist)), ", "))
+	}
+	if len(bot.SymbolWhitelist) > 0 {
+		logInfo.Printf("Whitelist: only buying %s\n", strings.Join(slices.Sorted(maps.Keys(bot.SymbolWhitelist)), ", "))
+	}
+	if bot.ExitOrderType == exitOrderMarket {
+		logInfo.Println("Exit orders: MARKET - sells when a cycle sees the target price; fill is guaranteed but the")
+		logInfo.Println("  price is not, and spikes between cycles can be missed")
+	} else {
+		logInfo.Println("Exit orders: LIMIT - resting GTC sell at the target; price is guaranteed but the fill is not")
+		logInfo.Println("  if price spikes and retraces before reaching the order")
+	}
+	if len(bot.SellTranches) > 0 {
+		tranches := make([]string, 0, len(bot.SellTranches))
+		for _, tranche := range bot.SellTranches {
+			tranches = append(tranches, fmt.Sprintf("%g%% at +%.1f%%", tranche.Fraction*100, tranche.ProfitPercent))
+		}
+		logInfo.Printf("Sell tranches: %s net profit\n", strings.Join(tranches, ", "))
+	}
+
+	// Real money is at stake, so make the user confirm the settings above before the first order
+	if !bot.DryRun && !bot.AutoConfirm {
+		if err := bot.confirmLiveTrading(os.Stdin); err != nil {
+			log.Fatalf("ERROR: %v", err)
+		}
+	}
+
+	// Optional HTTP server for health checks and status (pointless for a single cycle)
+	if port := os.Getenv("HTTP_PORT"); port != "" && !bot.RunOnce {
+		bot.startHTTPServer(port)
+	}
+
+	// Run initial cycle
+	err := bot.runCycle()
+
+	// A successful cycle has already saved state and printed stats
+	if bot.RunOnce {
+		if err != nil {
+			bot.saveState()
+			log.Fatalf("ERROR: Single trading cycle failed: %v", err)
+		}
+		logInfo.Println("\nRUN_ONCE: cycle complete, exiting")
+		return
+	}
+
+	skipNext := errors.Is(err, ErrRateLimited)
+
+	// A failed cycle is retried with backoff instead of waiting a full interval; a nil channel never fires
+	var retryC <-chan time.Time
+	retries := 0
+	scheduleRetry := func(err error) {
+		// A maintenance pause keeps its own cooldown, so it isn't retried either
+		if err == nil || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTradingPaused) || retries >= bot.CycleRetries {
+			if err != nil && retries > 0 {
+				logWarn.Printf("WARNING: Trading cycle still failing after %d retries, waiting for the next scheduled cycle\n", retries)
+			}
+			retryC, retries = nil, 0
+			return
+		}
+		delay := cycleRetryDelay(retries)
+		retries++
+		logInfo.Printf("Retrying the trading cycle in %s (attempt %d/%d)\n", delay, retries, bot.CycleRetries)
+		retryC = time.After(delay)
+	}
+	scheduleRetry(err)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Performance reports run on their own schedule; a nil channel never fires when they are disabled
+	var reportC <-chan time.Time
+	if bot.ReportInterval > 0 {
+		reportTicker := time.NewTicker(bot.ReportInterval)
+		defer reportTicker.Stop()
+		reportC = reportTicker.C
+		logInfo.Printf("Performance report every %s\n", bot.ReportInterval)
+	}
+
+	logInfo.Printf("\nBot will run every %s. Press Ctrl+C to stop.\n", interval)
+
+	for {
+		select {
+		case <-ticker.C:
+			// Give the CMC quota a full interval to recover after a rate limit
+			if skipNext {
+				skipNext = false
+				logInfo.Println("Skipping this cycle to back off after CoinMarketCap rate limiting")
+				continue
+			}
+
+			// The scheduled cycle replaces any pending retry and starts a fresh backoff
+			retryC, retries = nil, 0
+			err := bot.runCycle()
+			skipNext = errors.Is(err, ErrRateLimited)
+			scheduleRetry(err)
+		case <-retryC:
+			err := bot.runCycle()
+			skipNext = errors.Is(err, ErrRateLimited)
+			scheduleRetry(err)
+		case <-reportC:
+			bot.sendReport()
+		}
+	}
+}
+
+// initBotFromAccount checks credentials, fetches the real USDT balance and creates the bot, exiting on failure
+func initBotFromAccount() *TradingBot {
+	// Check API credentials first
+	apiKey := os.Getenv("BINANCE_API_KEY")
+	secretKey := os.Getenv("BINANCE_SECRET_KEY")
+	cmcKey := os.Getenv("COIN_MARKET_CAP_API_KEY")
+
+	dryRun := getEnvBool("DRY_RUN", false)
+
+	if !dryRun && (apiKey == "" || secretKey == "") {
+		log.Fatalf("ERROR: BINANCE API KEYS REQUIRED! Set BINANCE_API_KEY and BINANCE_SECRET_KEY in .env file")
+	}
+	if !dryRun {
+		if err := validateBinanceKeys(apiKey, secretKey); err != nil {
+			log.Fatalf("ERROR: Invalid Binance API keys: %v", err)
+		}
+	}
+
+	if source, _ := parseDataSource(os.Getenv("DATA_SOURCE")); source == dataSourceCMC && cmcKey == "" {
+		log.Fatalf("ERROR: COINMARKETCAP API KEY REQUIRED! Set COIN_MARKET_CAP_API_KEY in .env file, or use DATA_SOURCE=binance or coingecko")
+	}
+
+	var realBalance float64
+	if dryRun {
+		// Paper trading starts from a configurable simulated balance
+		realBalance = getEnvFloat("DRY_RUN_BALANCE", 100.0)
+		logInfo.Printf("\nDRY RUN: Simulated USDT Balance: %.2f USDT\n", realBalance)
+	} else {
+		// Fetch real USDT balance from Binance
+		logInfo.Printf("\nFetching real USDT balance from Binance (%s)...\n", binanceBaseURL())
+
+		client := newHTTPClient()
+		binance := NewBinanceClient(BinanceConfig{APIKey: apiKey, SecretKey: secretKey, BaseURL: binanceBaseURL()}, client)
+		if err := syncServerTime(client, binanceBaseURL()); err != nil {
+			log.Printf("WARNING: Could not sync with Binance server time, using local clock: %v", err)
+		}
+
+		// Confirm the keys actually authenticate before anything else touches the account
+		if err := pingBinanceAuth(binance); err != nil {
+			log.Fatalf("ERROR: Binance API key check failed: %v", err)
+		}
+		logInfo.Println("SUCCESS: Binance API keys authenticated")
+
+		var err error
+		realBalance, err = getRealUSDTBalance(binance)
+		if err != nil {
+			log.Fatalf("ERROR: Failed to fetch real USDT balance: %v", err)
+		}
+
+		logInfo.Printf("SUCCESS: Real USDT Balance: %.2f USDT\n", realBalance)
+	}
+
+	if realBalance < 20.0 {
+		logWarn.Printf("WARNING: Low balance detected (%.2f USDT). Consider reducing INVESTMENT_PER_TRADE.\n", realBalance)
+	}
+
+	// Initialize bot using real balance
+	bot, err := NewTradingBot(realBalance)
+	if err != nil {
+		log.Fatalf("Failed to initialize trading bot: %v", err)
+	}
+
+	return bot
+}
+
+// PreviewTradingBot runs the analysis once against live data and prints what would be bought, without trading
+func PreviewTradingBot() {
+	logInfo.Println("=== PREVIEW: What the bot would do right now (no orders are placed) ===")
+
+	bot := initBotFromAccount()
+	bot.PreviewMode = true
+	bot.ExplainMode = true
+
+	watchList, err := bot.fetchWatchList()
+	if err != nil {
+		log.Fatalf("ERROR: Failed to fetch market data: %v", err)
+	}
+	bot.WatchList = watchList
+
+	bot.analyzeTradingOpportunities()
+
+	logInfo.Printf("\nPreview complete - budget after planned buys: %.2f of %.2f USDT\n",
+		bot.AvailableBudget, bot.TotalBudget)
+}
+
+// StartTradingBot is the entry point for the optimized trading bot
+func StartTradingBot() {
+	logInfo.Println("=== OPTIMIZED Crypto Trading Bot ===")
+	logInfo.Println("Data Strategy: CoinMarketCap API (Top 20 non-stablecoins)")
+	arrow := emoji("\u2192", "->")
+	logInfo.Printf("Trading Strategy: Dip buys %s configurable profit target (default 5-10%% drops %s 5%%)\n", arrow, arrow)
+	logInfo.Println("Execution Platform: Binance API (buy/sell only)")
+
+	bot := initBotFromAccount()
+
+	// Pick up positions from a previous run so their sell orders stay tracked
+	bot.restoreState(stateFilePath())
+
+	if err := bot.validateStartupBudget(); err != nil {
+		log.Fatalf("ERROR: Startup validation failed: %v", err)
 	}
 
-	// Start continuous trading with 5-minute intervals
-	fmt.Println("\nStarting optimized trading mode...")
-	fmt.Println("CoinMarketCap: Real-time top 20 data")
-	fmt.Println("Binance: Trading execution only")
-	fmt.Println("Strategy: Buy 5-10% drops, Sell +5% profit")
+	// Start continuous trading
+	logInfo.Println("\nStarting optimized trading mode...")
+	logInfo.Printf("Market data: %s\n", bot.dataSourceLabel())
+	logInfo.Println("Binance: Trading execution only")
+	logInfo.Printf("Strategy: Buy %s drops, Sell +%.1f%% net profit\n", bot.dropBandLabel(), bot.ProfitTargetPercent)
 	bot.startBot()
 }
@@ -0,0 +1,806 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFillCommissions(t *testing.T) {
+	tests := []struct {
+		name          string
+		fills         []Fill
+		wantBaseQty   float64
+		wantQuoteCost float64
+		wantBNBCost   float64
+	}{
+		{
+			name: "commission in base asset reduces quantity",
+			fills: []Fill{
+				{Price: "2.00", Qty: "3.0", Commission: "0.003", CommissionAsset: "ADA"},
+				{Price: "2.01", Qty: "0.5", Commission: "0.0005", CommissionAsset: "ADA"},
+			},
+			wantBaseQty: 0.0035,
+		},
+		{
+			name: "commission in BNB is a cash cost",
+			fills: []Fill{
+				{Price: "2.00", Qty: "3.5", Commission: "0.00001", CommissionAsset: "BNB"},
+			},
+			wantBNBCost: 0.00001,
+		},
+		{
+			name: "commission in quote asset is a cash cost",
+			fills: []Fill{
+				{Price: "2.00", Qty: "3.5", Commission: "0.007", CommissionAsset: "USDT"},
+			},
+			wantQuoteCost: 0.007,
+		},
+		{
+			name:  "no fills",
+			fills: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseQty, quoteCost, bnbCost := fillCommissions(tt.fills, "ADA")
+			if math.Abs(baseQty-tt.wantBaseQty) > 1e-12 {
+				t.Errorf("baseQty = %v, want %v", baseQty, tt.wantBaseQty)
+			}
+			if math.Abs(quoteCost-tt.wantQuoteCost) > 1e-12 {
+				t.Errorf("quoteCost = %v, want %v", quoteCost, tt.wantQuoteCost)
+			}
+			if math.Abs(bnbCost-tt.wantBNBCost) > 1e-12 {
+				t.Errorf("bnbCost = %v, want %v", bnbCost, tt.wantBNBCost)
+			}
+		})
+	}
+}
+
+func TestFillCommissionsBNBAsBaseAsset(t *testing.T) {
+	// When trading BNBUSDT, BNB commission is taken from the bought quantity
+	fills := []Fill{{Price: "600", Qty: "0.01", Commission: "0.00001", CommissionAsset: "BNB"}}
+
+	baseQty, quoteCost, bnbCost := fillCommissions(fills, "BNB")
+	if baseQty != 0.00001 || quoteCost != 0 || bnbCost != 0 {
+		t.Errorf("got base=%v quote=%v bnb=%v, want base=0.00001 only", baseQty, quoteCost, bnbCost)
+	}
+}
+
+func TestAvgFillPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		fills     []Fill
+		lastPrice float64
+		want      float64
+	}{
+		{
+			name:      "single fill",
+			fills:     []Fill{{Price: "0.5000", Qty: "20.0"}},
+			lastPrice: 0.49,
+			want:      0.5,
+		},
+		{
+			name: "multiple fills are weighted by quantity",
+			fills: []Fill{
+				{Price: "0.5000", Qty: "12.0"},
+				{Price: "0.5125", Qty: "8.0"},
+			},
+			lastPrice: 0.49,
+			want:      0.505, // (6.0 + 4.1) / 20
+		},
+		{
+			name: "equal quantities average evenly",
+			fills: []Fill{
+				{Price: "100.00", Qty: "0.5"},
+				{Price: "102.00", Qty: "0.5"},
+			},
+			lastPrice: 99,
+			want:      101,
+		},
+		{
+			name:      "no fills falls back to the last price",
+			fills:     nil,
+			lastPrice: 0.49,
+			want:      0.49,
+		},
+		{
+			name:      "zero-quantity fills fall back to the last price",
+			fills:     []Fill{{Price: "0.5000", Qty: "0"}},
+			lastPrice: 0.49,
+			want:      0.49,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := averageFillPrice(&OrderResponse{Fills: tt.fills}, tt.lastPrice)
+			if !approxEqual(got, tt.want) {
+				t.Errorf("averageFillPrice() = %v, want %v", got, tt.want)
+			}
+			if len(tt.fills) == 0 && avgFillPrice(tt.fills) != 0 {
+				t.Errorf("avgFillPrice(nil) = %v, want 0", avgFillPrice(tt.fills))
+			}
+		})
+	}
+}
+
+func TestRoundToStepSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity float64
+		stepSize string
+		want     float64
+	}{
+		{name: "step 0.001 rounds down", quantity: 1.23456, stepSize: "0.00100000", want: 1.234},
+		{name: "step 0.001 exact multiple", quantity: 0.29, stepSize: "0.00100000", want: 0.29},
+		{name: "step 1 drops fraction", quantity: 42.999, stepSize: "1.00000000", want: 42},
+		{name: "step 1 below one step", quantity: 0.75, stepSize: "1.00000000", want: 0},
+		{name: "step 0.00001", quantity: 0.0123456789, stepSize: "0.00001000", want: 0.01234},
+		{name: "invalid step leaves quantity", quantity: 1.23456, stepSize: "", want: 1.23456},
+		{name: "zero step leaves quantity", quantity: 1.23456, stepSize: "0", want: 1.23456},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundToStepSize(tt.quantity, tt.stepSize); got != tt.want {
+				t.Errorf("roundToStepSize(%v, %q) = %v, want %v", tt.quantity, tt.stepSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSizeDecimals(t *testing.T) {
+	tests := []struct {
+		size string
+		want int
+	}{
+		{size: "0.00100000", want: 3},
+		{size: "0.01000000", want: 2},
+		{size: "0.00000001", want: 8},
+		{size: "1.00000000", want: 0},
+		{size: "10.00000000", want: 0},
+		{size: "0.1", want: 1},
+		{size: "1", want: 0},
+		{size: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			if got := sizeDecimals(tt.size); got != tt.want {
+				t.Errorf("sizeDecimals(%q) = %d, want %d", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderPrecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters SymbolFilters
+		want    OrderPrecision
+	}{
+		{name: "step and tick", filters: SymbolFilters{StepSize: "0.00100000", TickSize: "0.01000000"}, want: OrderPrecision{Quantity: 3, Price: 2}},
+		{name: "whole step", filters: SymbolFilters{StepSize: "1.00000000", TickSize: "0.00000100"}, want: OrderPrecision{Quantity: 0, Price: 6}},
+		{name: "missing sizes keep default", filters: SymbolFilters{}, want: defaultOrderPrecision},
+		{name: "zero tick keeps default price", filters: SymbolFilters{StepSize: "0.10000000", TickSize: "0.00000000"}, want: OrderPrecision{Quantity: 1, Price: 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.orderPrecision(); got != tt.want {
+				t.Errorf("orderPrecision() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		value    float64
+		decimals int
+		want     string
+	}{
+		{value: 1.234, decimals: 3, want: "1.234"},
+		{value: 1.2, decimals: 3, want: "1.2"},
+		{value: 42, decimals: 3, want: "42"},
+		{value: 42, decimals: 0, want: "42"},
+		{value: 100, decimals: 2, want: "100"},
+		{value: 0.00001234, decimals: 8, want: "0.00001234"},
+		{value: 1.2340000000000002, decimals: 8, want: "1.234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatDecimal(tt.value, tt.decimals); got != tt.want {
+				t.Errorf("formatDecimal(%v, %d) = %q, want %q", tt.value, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+// approxEqual compares floats with a tolerance, since rounding multiplies the tick count back out
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestRoundToTickSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		tickSize string
+		want     float64
+	}{
+		{name: "tick 0.01 rounds down", price: 1.234, tickSize: "0.01000000", want: 1.23},
+		{name: "tick 0.01 rounds up", price: 1.236, tickSize: "0.01000000", want: 1.24},
+		{name: "tick 0.01 half rounds up", price: 1.005, tickSize: "0.01000000", want: 1.01},
+		{name: "tick 0.01 exact multiple", price: 0.29, tickSize: "0.01000000", want: 0.29},
+		{name: "tick 0.01 float noise", price: 0.1 + 0.2, tickSize: "0.01000000", want: 0.3},
+		{name: "tick 0.0001 rounds down", price: 0.123449, tickSize: "0.00010000", want: 0.1234},
+		{name: "tick 0.0001 half rounds up", price: 0.12345, tickSize: "0.00010000", want: 0.1235},
+		{name: "tick 1 rounds down", price: 42.49, tickSize: "1.00000000", want: 42},
+		{name: "tick 1 half rounds up", price: 42.5, tickSize: "1.00000000", want: 43},
+		{name: "tick 0.5 rounds down", price: 10.2, tickSize: "0.50000000", want: 10},
+		{name: "tick 0.5 half rounds up", price: 10.25, tickSize: "0.50000000", want: 10.5},
+		{name: "tick 0.5 rounds up", price: 10.74, tickSize: "0.50000000", want: 10.5},
+		{name: "tick 0.5 rounds to whole", price: 10.75, tickSize: "0.50000000", want: 11},
+		{name: "tiny price tick 0.00000001", price: 0.0000123456, tickSize: "0.00000001", want: 0.00001235},
+		{name: "invalid tick leaves price", price: 1.23456, tickSize: "abc", want: 1.23456},
+		{name: "empty tick leaves price", price: 1.23456, tickSize: "", want: 1.23456},
+		{name: "zero tick leaves price", price: 1.23456, tickSize: "0", want: 1.23456},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundToTickSize(tt.price, tt.tickSize, RoundNearest); !approxEqual(got, tt.want) {
+				t.Errorf("roundToTickSize(%v, %q) = %v, want %v", tt.price, tt.tickSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundToTickSizeModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		tickSize string
+		mode     RoundingMode
+		want     float64
+	}{
+		{name: "nearest below half", price: 1.234, tickSize: "0.01", mode: RoundNearest, want: 1.23},
+		{name: "nearest half", price: 1.235, tickSize: "0.01", mode: RoundNearest, want: 1.24},
+		{name: "up just above tick", price: 1.231, tickSize: "0.01", mode: RoundUp, want: 1.24},
+		{name: "up below half", price: 1.234, tickSize: "0.01", mode: RoundUp, want: 1.24},
+		{name: "up exact multiple", price: 1.23, tickSize: "0.01", mode: RoundUp, want: 1.23},
+		{name: "up float noise", price: 0.1 + 0.2, tickSize: "0.01", mode: RoundUp, want: 0.3},
+		{name: "up tick 0.5", price: 10.01, tickSize: "0.5", mode: RoundUp, want: 10.5},
+		{name: "down just below tick", price: 1.239, tickSize: "0.01", mode: RoundDown, want: 1.23},
+		{name: "down above half", price: 1.236, tickSize: "0.01", mode: RoundDown, want: 1.23},
+		{name: "down exact multiple", price: 1.23, tickSize: "0.01", mode: RoundDown, want: 1.23},
+		{name: "down stored below tick", price: 1.005, tickSize: "0.005", mode: RoundDown, want: 1.005},
+		{name: "down tick 1", price: 42.99, tickSize: "1", mode: RoundDown, want: 42},
+		{name: "up invalid tick leaves price", price: 1.23456, tickSize: "abc", mode: RoundUp, want: 1.23456},
+		{name: "down zero tick leaves price", price: 1.23456, tickSize: "0", mode: RoundDown, want: 1.23456},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundToTickSize(tt.price, tt.tickSize, tt.mode); !approxEqual(got, tt.want) {
+				t.Errorf("roundToTickSize(%v, %q, %v) = %v, want %v", tt.price, tt.tickSize, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyDropSignal(t *testing.T) {
+	// The default thresholds: buy band -5% to -10%, safety limit -11%
+	bot := &TradingBot{BuyDropMin: -5.0, BuyDropMax: -10.0, SafetyDropLimit: -11.0}
+
+	tests := []struct {
+		pct  float64
+		want Signal
+	}{
+		{pct: 3.0, want: SignalHold},
+		{pct: 0, want: SignalHold},
+		{pct: -4.4, want: SignalHold},
+		{pct: -4.5, want: SignalWatch},
+		{pct: -4.99, want: SignalWatch},
+		{pct: -5.0, want: SignalBuy},
+		{pct: -7.5, want: SignalBuy},
+		{pct: -9.99, want: SignalBuy},
+		{pct: -10.0, want: SignalRisky},
+		{pct: -10.5, want: SignalRisky},
+		{pct: -11.0, want: SignalDanger},
+		{pct: -25.0, want: SignalDanger},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%.2f%%", tt.pct), func(t *testing.T) {
+			if got := bot.classifyDropSignal(tt.pct); got != tt.want {
+				t.Errorf("classifyDropSignal(%v) = %v, want %v", tt.pct, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteBuyOrder(t *testing.T) {
+	const apiKey, secretKey = "test-api-key", "test-secret-key"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/exchangeInfo":
+			fmt.Fprint(w, `{"symbols":[{"symbol":"ADAUSDT","status":"TRADING","quoteAsset":"USDT","quoteAssetPrecision":2,
+				"filters":[{"filterType":"LOT_SIZE","stepSize":"0.10000000"},{"filterType":"PRICE_FILTER","tickSize":"0.00010000"}]}]}`)
+		case "/api/v3/order":
+			if r.Method != http.MethodPost {
+				t.Errorf("method = %s, want POST", r.Method)
+			}
+			if got := r.Header.Get("X-MBX-APIKEY"); got != apiKey {
+				t.Errorf("X-MBX-APIKEY = %q, want %q", got, apiKey)
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			queryString, signature, ok := strings.Cut(string(body), "&signature=")
+			if !ok {
+				t.Errorf("request body has no signature: %s", body)
+			}
+			mac := hmac.New(sha256.New, []byte(secretKey))
+			mac.Write([]byte(queryString))
+			if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+				t.Errorf("signature = %s, want %s", signature, want)
+			}
+
+			params, err := url.ParseQuery(queryString)
+			if err != nil {
+				t.Errorf("parsing query string: %v", err)
+			}
+			want := map[string]string{"symbol": "ADAUSDT", "side": "BUY", "type": "MARKET", "quoteOrderQty": "10.12"}
+			for key, value := range want {
+				if got := params.Get(key); got != value {
+					t.Errorf("param %s = %q, want %q", key, got, value)
+				}
+			}
+			if params.Get("timestamp") == "" || params.Get("recvWindow") == "" {
+				t.Errorf("timestamp and recvWindow must be set, got %q", queryString)
+			}
+
+			fmt.Fprint(w, `{"symbol":"ADAUSDT","orderId":42,"status":"FILLED","type":"MARKET","side":"BUY",
+				"executedQty":"20.0","cummulativeQuoteQty":"10.10",
+				"fills":[{"price":"0.5000","qty":"12.0","commission":"0.012","commissionAsset":"ADA"},
+					{"price":"0.5125","qty":"8.0","commission":"0.008","commissionAsset":"ADA"}]}`)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	bot := &TradingBot{
+		Binance:    NewBinanceClient(BinanceConfig{APIKey: apiKey, SecretKey: secretKey, BaseURL: server.URL}, server.Client()),
+		httpClient: server.Client(),
+	}
+
+	order, err := bot.executeBuyOrder("ADAUSDT", 10.129)
+	if err != nil {
+		t.Fatalf("executeBuyOrder returned error: %v", err)
+	}
+
+	if order.OrderID != 42 || order.Status != StatusFilled || order.Side != SideBuy || order.Type != OrderTypeMarket {
+		t.Errorf("order = %+v, want filled market buy 42", order)
+	}
+	if order.ExecutedQty != "20.0" || order.QuoteQty != "10.10" {
+		t.Errorf("executedQty/quoteQty = %s/%s, want 20.0/10.10", order.ExecutedQty, order.QuoteQty)
+	}
+	if len(order.Fills) != 2 {
+		t.Fatalf("got %d fills, want 2", len(order.Fills))
+	}
+	if fill := order.Fills[1]; fill.Price != "0.5125" || fill.Qty != "8.0" || fill.Commission != "0.008" || fill.CommissionAsset != "ADA" {
+		t.Errorf("second fill = %+v", fill)
+	}
+}
+
+func TestExecuteBuyOrderBinanceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/exchangeInfo" {
+			fmt.Fprint(w, `{"symbols":[{"symbol":"ADAUSDT","quoteAssetPrecision":8}]}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`)
+	}))
+	defer server.Close()
+
+	bot := &TradingBot{
+		Binance:    NewBinanceClient(BinanceConfig{APIKey: "key", SecretKey: "secret", BaseURL: server.URL}, server.Client()),
+		httpClient: server.Client(),
+	}
+
+	_, err := bot.executeBuyOrder("ADAUSDT", 10)
+	if err == nil {
+		t.Fatal("executeBuyOrder succeeded, want an error")
+	}
+	if code := binanceErrorCode(err); code != binanceCodeNewOrderRejected {
+		t.Errorf("binanceErrorCode = %d, want %d (err: %v)", code, binanceCodeNewOrderRejected, err)
+	}
+}
+
+func TestRestoreStateDryRunKeepsBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot_state.dryrun.json")
+
+	saved := &TradingBot{
+		DryRun:          true,
+		StartTime:       time.Now().Add(-time.Hour),
+		TotalBudget:     1012.5,
+		AvailableBudget: 912.5,
+		NextPositionID:  3,
+		Positions: []TradingPosition{
+			{ID: 2, Symbol: "ADAUSDT", Quantity: 200, BuyPrice: 0.5, InvestedAmount: 100},
+		},
+		CompletedTrades: []CompletedTrade{{ID: 1, Symbol: "XRPUSDT", Profit: 12.5}},
+	}
+	if err := saved.SaveState(path); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+
+	// A restart starts from DRY_RUN_BALANCE, as initBotFromAccount does
+	bot := &TradingBot{DryRun: true, TotalBudget: 1000, AvailableBudget: 1000, NextPositionID: 1}
+	bot.restoreState(path)
+
+	if bot.AvailableBudget != saved.AvailableBudget || bot.TotalBudget != saved.TotalBudget {
+		t.Errorf("budget after restore = %.2f available / %.2f total, want %.2f / %.2f",
+			bot.AvailableBudget, bot.TotalBudget, saved.AvailableBudget, saved.TotalBudget)
+	}
+	if len(bot.Positions) != 1 || bot.NextPositionID != 3 {
+		t.Errorf("restored %d positions with next ID %d, want 1 and 3", len(bot.Positions), bot.NextPositionID)
+	}
+}
+
+func TestParseSellTranches(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []sellTranche
+		wantErr bool
+	}{
+		{name: "empty disables tranches", raw: "", want: nil},
+		{name: "sorted by percent", raw: "6:0.5, 3:0.5", want: []sellTranche{{3, 0.5}, {6, 0.5}}},
+		{name: "thirds within tolerance", raw: "2:0.3333333,4:0.3333333,6:0.3333334",
+			want: []sellTranche{{2, 0.3333333}, {4, 0.3333333}, {6, 0.3333334}}},
+		{name: "trailing comma ignored", raw: "5:1,", want: []sellTranche{{5, 1}}},
+		{name: "fractions under 1", raw: "3:0.5,6:0.4", wantErr: true},
+		{name: "fractions over 1", raw: "3:0.6,6:0.6", wantErr: true},
+		{name: "zero fraction", raw: "3:0,6:1", wantErr: true},
+		{name: "fraction above 1", raw: "3:1.5", wantErr: true},
+		{name: "non-numeric fraction", raw: "3:half", wantErr: true},
+		{name: "negative percent", raw: "-3:1", wantErr: true},
+		{name: "missing separator", raw: "3-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSellTranches(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSellTranches(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseSellTranches(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tranche %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitPosition(t *testing.T) {
+	pos := TradingPosition{Symbol: "ADAUSDT", Quantity: 10, InvestedAmount: 5, FeesPaid: 0.01, BaseFeesPaid: 0.02}
+
+	sold, rest := splitPosition(pos, 4)
+	if sold.Quantity != 4 || !approxEqual(sold.InvestedAmount, 2) || !approxEqual(sold.FeesPaid, 0.004) ||
+		!approxEqual(sold.BaseFeesPaid, 0.008) {
+		t.Errorf("sold = %+v, want 4 ADA costing 2 USDT with 40%% of the fees", sold)
+	}
+	if !approxEqual(rest.Quantity, 6) || !approxEqual(rest.InvestedAmount, 3) || !approxEqual(rest.FeesPaid, 0.006) ||
+		!approxEqual(rest.BaseFeesPaid, 0.012) {
+		t.Errorf("rest = %+v, want 6 ADA costing 3 USDT with 60%% of the fees", rest)
+	}
+	if !approxEqual(sold.InvestedAmount+rest.InvestedAmount, pos.InvestedAmount) {
+		t.Errorf("split cost %v + %v, want %v", sold.InvestedAmount, rest.InvestedAmount, pos.InvestedAmount)
+	}
+}
+
+// newTrancheTestBot returns a dry-run bot with cached ADAUSDT filters and a ticker quoting ADA at $1,
+// so tranche orders are simulated without the exchange
+func newTrancheTestBot(t *testing.T, tranches []sellTranche) (*TradingBot, *SymbolFilters) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/ticker/price" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"symbol":"ADAUSDT","price":"1.00000000"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	filters := &SymbolFilters{StepSize: "0.10000000", TickSize: "0.00010000", MinNotional: "1.00000000"}
+	bot := &TradingBot{
+		Binance:         NewBinanceClient(BinanceConfig{BaseURL: server.URL}, server.Client()),
+		httpClient:      server.Client(),
+		DryRun:          true,
+		SellTranches:    tranches,
+		SellRounding:    RoundUp,
+		SellMaxRetries:  1,
+		ExchangeInfoTTL: time.Hour,
+		symbolFilters:   map[string]cachedSymbolFilters{"ADAUSDT": {filters: filters, fetchedAt: time.Now()}},
+	}
+	return bot, filters
+}
+
+func TestPlaceSellTranchesRemainder(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity float64
+		tranches []sellTranche
+		want     []float64
+	}{
+		{name: "even halves", quantity: 10, tranches: []sellTranche{{3, 0.5}, {6, 0.5}}, want: []float64{5, 5}},
+		{name: "last tranche takes the remainder", quantity: 10,
+			tranches: []sellTranche{{2, 0.3333333}, {4, 0.3333333}, {6, 0.3333334}}, want: []float64{3.3, 3.3, 3.4}},
+		{name: "remainder rounded down to the step", quantity: 10.05,
+			tranches: []sellTranche{{3, 0.5}, {6, 0.5}}, want: []float64{5, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, filters := newTrancheTestBot(t, tt.tranches)
+			pos := &TradingPosition{Symbol: "ADAUSDT", Quantity: tt.quantity, BuyPrice: 1, InvestedAmount: tt.quantity}
+
+			if !bot.placeSellTranches(pos, filters) {
+				t.Fatal("placeSellTranches fell back to a single sell")
+			}
+			if len(pos.Tranches) != len(tt.want) {
+				t.Fatalf("placed %d tranches, want %d", len(pos.Tranches), len(tt.want))
+			}
+			for i, tranche := range pos.Tranches {
+				if !approxEqual(tranche.Quantity, tt.want[i]) {
+					t.Errorf("tranche %d quantity = %v, want %v", i+1, tranche.Quantity, tt.want[i])
+				}
+			}
+			if !pos.HasActiveSellOrder || pos.SellOrderID != pos.Tranches[0].OrderID {
+				t.Errorf("position sell order = %d (active %v), want first tranche %d",
+					pos.SellOrderID, pos.HasActiveSellOrder, pos.Tranches[0].OrderID)
+			}
+		})
+	}
+}
+
+func TestPlaceSellTranchesMinNetProfit(t *testing.T) {
+	bot, filters := newTrancheTestBot(t, []sellTranche{{1, 0.5}, {2, 0.5}})
+	bot.MinNetProfit = 0.2 // The tranches net 0.05 + 0.10 USDT on 10 USDT
+	pos := &TradingPosition{Symbol: "ADAUSDT", Quantity: 10, BuyPrice: 1, InvestedAmount: 10}
+
+	if bot.placeSellTranches(pos, filters) {
+		t.Fatal("placeSellTranches placed tranches netting below MIN_NET_PROFIT_USDT")
+	}
+	if len(pos.Tranches) != 0 || pos.HasActiveSellOrder {
+		t.Errorf("position = %+v, want no sell orders", pos)
+	}
+}
+
+func TestReconcileTranches(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        OrderStatus
+		executedQty   string
+		quoteQty      string
+		wantOpen      bool
+		wantQuantity  float64
+		wantTrades    int
+		wantTranches  int
+		wantActive    bool
+		wantReplaced  float64
+		wantCancelled bool
+	}{
+		{name: "filled tranche booked", status: StatusFilled, executedQty: "5", quoteQty: "5.15",
+			wantOpen: true, wantQuantity: 5, wantTrades: 1, wantTranches: 1, wantActive: true},
+		{name: "partial fill re-placed", status: StatusCanceled, executedQty: "2", quoteQty: "2.06",
+			wantOpen: true, wantQuantity: 8, wantTrades: 1, wantTranches: 2, wantActive: true, wantReplaced: 3},
+		{name: "expired without fill re-placed", status: StatusExpired, executedQty: "0", quoteQty: "0",
+			wantOpen: true, wantQuantity: 10, wantTranches: 2, wantActive: true, wantReplaced: 5},
+		{name: "unsold dust releases the rest", status: StatusCanceled, executedQty: "4.5", quoteQty: "4.635",
+			wantOpen: true, wantQuantity: 5.5, wantTrades: 1, wantCancelled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, _ := newTrancheTestBot(t, []sellTranche{{3, 0.5}, {6, 0.5}})
+			first := bot.newDryRunOrder("ADAUSDT", SideSell, OrderTypeLimit, StatusNew, 1.03, 5)
+			second := bot.newDryRunOrder("ADAUSDT", SideSell, OrderTypeLimit, StatusNew, 1.06, 5)
+
+			// The first tranche ends in the tested state; the second stays open below its price
+			done := bot.DryRunOrders[first.OrderID]
+			done.Status, done.ExecutedQty, done.QuoteQty = tt.status, tt.executedQty, tt.quoteQty
+			bot.DryRunOrders[first.OrderID] = done
+
+			pos := TradingPosition{
+				Symbol: "ADAUSDT", Quantity: 10, BuyPrice: 1, InvestedAmount: 10, BuyTime: time.Now(),
+				HasActiveSellOrder: true, SellOrderID: first.OrderID,
+				Tranches: []TrancheOrder{
+					{OrderID: first.OrderID, Price: 1.03, Quantity: 5},
+					{OrderID: second.OrderID, Price: 1.06, Quantity: 5},
+				},
+			}
+
+			got, open := bot.reconcileTranches(pos)
+			if open != tt.wantOpen || !approxEqual(got.Quantity, tt.wantQuantity) {
+				t.Errorf("rest = %.8f (open %v), want %.8f (open %v)", got.Quantity, open, tt.wantQuantity, tt.wantOpen)
+			}
+			if len(bot.CompletedTrades) != tt.wantTrades {
+				t.Errorf("booked %d trades, want %d", len(bot.CompletedTrades), tt.wantTrades)
+			}
+			if len(got.Tranches) != tt.wantTranches || got.HasActiveSellOrder != tt.wantActive {
+				t.Errorf("%d tranches (active %v), want %d (active %v)",
+					len(got.Tranches), got.HasActiveSellOrder, tt.wantTranches, tt.wantActive)
+			}
+			if tt.wantReplaced > 0 {
+				replaced := got.Tranches[0] // Re-placed in the first tranche's slot
+				if replaced.OrderID == first.OrderID || replaced.Price != 1.03 || !approxEqual(replaced.Quantity, tt.wantReplaced) {
+					t.Errorf("re-placed tranche = %+v, want %.8f at $1.03 under a new order", replaced, tt.wantReplaced)
+				}
+			}
+			if _, resting := bot.DryRunOrders[second.OrderID]; resting == tt.wantCancelled {
+				t.Errorf("second tranche resting = %v, want cancelled %v", resting, tt.wantCancelled)
+			}
+		})
+	}
+}
+
+func TestParseBinanceErrorUnavailable(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		wantUnavailable bool
+		wantCode        int
+	}{
+		{name: "503 without body", status: http.StatusServiceUnavailable, body: "", wantUnavailable: true},
+		{name: "503 with html page", status: http.StatusServiceUnavailable, body: "<html>maintenance</html>", wantUnavailable: true},
+		{name: "503 with error body", status: http.StatusServiceUnavailable, body: `{"code":-1001,"msg":"Internal error"}`,
+			wantUnavailable: true, wantCode: -1001},
+		{name: "500 without body", status: http.StatusInternalServerError, body: ""},
+		{name: "400 with error body", status: http.StatusBadRequest,
+			body: `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`, wantCode: -2010},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseBinanceError(tt.status, []byte(tt.body))
+			if got := isBinanceUnavailable(err); got != tt.wantUnavailable {
+				t.Errorf("isBinanceUnavailable(%v) = %v, want %v", err, got, tt.wantUnavailable)
+			}
+			if got := isBinanceUnavailable(fmt.Errorf("error placing order: %w", err)); got != tt.wantUnavailable {
+				t.Errorf("isBinanceUnavailable on wrapped %v = %v, want %v", err, got, tt.wantUnavailable)
+			}
+			if code := binanceErrorCode(err); code != tt.wantCode {
+				t.Errorf("binanceErrorCode(%v) = %d, want %d", err, code, tt.wantCode)
+			}
+		})
+	}
+
+	if isBinanceUnavailable(nil) || isBinanceUnavailable(io.EOF) {
+		t.Error("isBinanceUnavailable reported a non-Binance error as unavailable")
+	}
+}
+
+func TestMaintenanceTransitions(t *testing.T) {
+	pingStatus := http.StatusServiceUnavailable
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/ping" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		pings++
+		w.WriteHeader(pingStatus)
+		if pingStatus == http.StatusOK {
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	bot := &TradingBot{
+		Binance:             NewBinanceClient(BinanceConfig{BaseURL: server.URL}, server.Client()),
+		httpClient:          server.Client(),
+		MaintenanceCooldown: time.Hour,
+	}
+	expireCooldown := func() {
+		bot.maintenanceMu.Lock()
+		bot.maintenanceUntil = time.Now().Add(-time.Second)
+		bot.maintenanceMu.Unlock()
+	}
+
+	if !bot.maintenanceOver() || bot.inMaintenance() {
+		t.Fatal("a fresh bot is paused for maintenance")
+	}
+
+	// Other order failures don't pause trading
+	rejected := parseBinanceError(http.StatusBadRequest, []byte(`{"code":-2010,"msg":"insufficient balance"}`))
+	if err := bot.watchMaintenance(rejected); err != rejected || bot.inMaintenance() {
+		t.Fatalf("watchMaintenance(%v) = %v, paused %v; want the error back and no pause", rejected, err, bot.inMaintenance())
+	}
+
+	unavailable := parseBinanceError(http.StatusServiceUnavailable, nil)
+	if err := bot.watchMaintenance(unavailable); err != unavailable || !bot.inMaintenance() {
+		t.Fatalf("watchMaintenance(%v) = %v, paused %v; want the error back and a pause", unavailable, err, bot.inMaintenance())
+	}
+	if bot.maintenanceOver() || pings != 0 {
+		t.Fatalf("maintenanceOver during the cooldown = true after %d pings, want false without pinging", pings)
+	}
+
+	// Still down after the cooldown: the pause is extended
+	expireCooldown()
+	if bot.maintenanceOver() || pings != 1 {
+		t.Fatalf("maintenanceOver with Binance down = true after %d pings, want false after 1", pings)
+	}
+	bot.maintenanceMu.Lock()
+	extended := time.Until(bot.maintenanceUntil) > 59*time.Minute
+	bot.maintenanceMu.Unlock()
+	if !extended || !bot.inMaintenance() {
+		t.Errorf("pause not extended by the cooldown after a failed ping")
+	}
+
+	// A paused cycle is recorded as a failure so /healthz doesn't report healthy
+	if err := bot.runCycle(); !errors.Is(err, ErrTradingPaused) {
+		t.Errorf("runCycle while paused = %v, want %v", err, ErrTradingPaused)
+	}
+	if bot.LastCycleError == "" || !bot.LastCycleTime.IsZero() {
+		t.Errorf("paused cycle recorded as a success (last error %q, last success %v)", bot.LastCycleError, bot.LastCycleTime)
+	}
+
+	// Back up after the next cooldown: trading resumes
+	expireCooldown()
+	pingStatus = http.StatusOK
+	if !bot.maintenanceOver() || bot.inMaintenance() || pings != 2 {
+		t.Errorf("maintenanceOver with Binance back = false or still paused after %d pings, want resumed after 2", pings)
+	}
+}
+
+func TestCancelSellOrdersBooksPartialFill(t *testing.T) {
+	bot, _ := newTrancheTestBot(t, nil)
+	order := bot.newDryRunOrder("ADAUSDT", SideSell, OrderTypeLimit, StatusNew, 1.05, 10)
+	partial := bot.DryRunOrders[order.OrderID]
+	partial.ExecutedQty, partial.QuoteQty = "4", "4.2"
+	bot.DryRunOrders[order.OrderID] = partial
+
+	pos := TradingPosition{
+		Symbol: "ADAUSDT", Quantity: 10, BuyPrice: 1, InvestedAmount: 10, BuyTime: time.Now(),
+		TargetSellPrice: 1.05, HasActiveSellOrder: true, SellOrderID: order.OrderID,
+	}
+	if err := bot.cancelSellOrders(&pos); err != nil {
+		t.Fatalf("cancelSellOrders: %v", err)
+	}
+
+	if !approxEqual(pos.Quantity, 6) || !approxEqual(pos.InvestedAmount, 6) || pos.HasActiveSellOrder {
+		t.Errorf("position after cancel = %.8f for %.2f USDT (active %v), want 6 for 6 USDT without an order",
+			pos.Quantity, pos.InvestedAmount, pos.HasActiveSellOrder)
+	}
+	if len(bot.CompletedTrades) != 1 || !approxEqual(bot.CompletedTrades[0].Quantity, 4) ||
+		!approxEqual(bot.CompletedTrades[0].SellPrice, 1.05) {
+		t.Errorf("completed trades = %+v, want 4 sold at $1.05", bot.CompletedTrades)
+	}
+}
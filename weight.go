@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Binance request weight budget per minute (BINANCE_WEIGHT_LIMIT) and when to react to it
+const (
+	defaultWeightLimit = 1200
+	weightWarnRatio    = 0.8 // Log a warning
+	weightPauseRatio   = 0.9 // Wait for the next minute window before sending more requests
+)
+
+// The latest X-MBX-USED-WEIGHT-1M Binance reported and the server time (ms) it was seen at
+var (
+	usedWeight1m atomic.Int64
+	usedWeightAt atomic.Int64
+)
+
+// weightTrackingTransport records the used request weight from every Binance response
+type weightTrackingTransport struct {
+	base http.RoundTripper
+}
+
+func (t weightTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		recordUsedWeight(resp.Header)
+	}
+	return resp, err
+}
+
+// recordUsedWeight stores the X-MBX-USED-WEIGHT-1M header when the response has one
+func recordUsedWeight(header http.Header) {
+	weight, err := strconv.ParseInt(header.Get("X-MBX-USED-WEIGHT-1M"), 10, 64)
+	if err != nil {
+		return
+	}
+	usedWeight1m.Store(weight)
+	usedWeightAt.Store(binanceTimestamp())
+}
+
+// currentUsedWeight returns the last reported weight, or 0 once Binance's minute window has rolled over
+func currentUsedWeight() int {
+	at := usedWeightAt.Load()
+	if at == 0 || at/60000 != binanceTimestamp()/60000 {
+		return 0
+	}
+	return int(usedWeight1m.Load())
+}
+
+// throttleRequestWeight stores the used weight on the bot, warns at 80% of the limit and pauses until
+// the minute window resets at 90%, so a busy cycle can't get the IP banned
+func (bot *TradingBot) throttleRequestWeight() {
+	bot.UsedWeight = currentUsedWeight()
+	limit := float64(bot.WeightLimit)
+	if limit <= 0 || float64(bot.UsedWeight) < limit*weightWarnRatio {
+		return
+	}
+
+	if float64(bot.UsedWeight) < limit*weightPauseRatio {
+		// One warning per minute window is enough
+		if window := binanceTimestamp() / 60000; window != bot.weightWarnedWindow {
+			bot.weightWarnedWindow = window
+			log.Printf("WARNING: Binance request weight at %d/%d this minute", bot.UsedWeight, bot.WeightLimit)
+		}
+		return
+	}
+
+	wait := weightResetDelay()
+	log.Printf("WARNING: Binance request weight at %d/%d - pausing %s until the limit resets",
+		bot.UsedWeight, bot.WeightLimit, wait.Round(time.Second))
+	time.Sleep(wait)
+	bot.UsedWeight = currentUsedWeight()
+}
+
+// weightResetDelay returns how long until the request weight minute window (server time) resets
+func weightResetDelay() time.Duration {
+	return time.Duration(60000-binanceTimestamp()%60000)*time.Millisecond + time.Second
+}